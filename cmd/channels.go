@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	flagPort             = "port"
+	flagCounterpartyPort = "counterparty-port"
+	flagVersion          = "version"
+	flagOrdering         = "ordering"
+)
+
+func ChannelsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "channels",
+		Short: "Create and list the channels between the solo machine and the chain",
+	}
+
+	cmd.AddCommand(channelsCreateCmd())
+	cmd.AddCommand(channelsListCmd())
+
+	return cmd
+}
+
+func channelsCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create --port [port] --chain-name [chain-name]",
+		Short: "Create a channel between a port on the solo machine and a port on the chain",
+		Long: `Create a channel between a port on the solo machine and a port on the chain.
+The handshake is started by the chain, and the port on the solo machine needs an application bound to it
+(the built-in transfer application, or the external state machine on the ports in its config).
+Running it again for the same ports after an interruption finishes the channel that is still in the handshake.
+The transfer, transfer-batch, offline transfer, packets and status commands only use the ICS20 channel created by init,
+so packets from the chain on another channel on the transfer port are received, but the solo machine does not send on it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			chainName := getChainName(cmd)
+			cdc := utils.SetupCodec()
+
+			portID, err := cmd.Flags().GetString(flagPort)
+			if err != nil {
+				return err
+			}
+			counterpartyPortID, err := cmd.Flags().GetString(flagCounterpartyPort)
+			if err != nil {
+				return err
+			}
+			if counterpartyPortID == "" {
+				counterpartyPortID = portID
+			}
+			version, err := cmd.Flags().GetString(flagVersion)
+			if err != nil {
+				return err
+			}
+			orderingStr, err := cmd.Flags().GetString(flagOrdering)
+			if err != nil {
+				return err
+			}
+			order, err := parseOrdering(orderingStr)
+			if err != nil {
+				return err
+			}
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			channelID, err := sm.CreateChannel(chainName, portID, counterpartyPortID, version, order)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("Channel created", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channelID))
+			cmd.Println(channelID)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagPort, "", "Port on the solo machine")
+	cmd.Flags().String(flagCounterpartyPort, "", "Port on the chain (the same as --port if empty)")
+	cmd.Flags().String(flagVersion, "", "Version proposed by the chain (the default version of the application on the chain if empty)")
+	cmd.Flags().String(flagOrdering, "unordered", "Channel ordering (ordered or unordered)")
+	_ = cmd.MarkFlagRequired(flagPort)
+
+	return cmd
+}
+
+func channelsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list --chain-name [chain-name]",
+		Short: "List the channels of the solo machine to the chain, in any state",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sm, err := localSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			channels, err := sm.Channels(getChainName(cmd))
+			if err != nil {
+				return err
+			}
+
+			if len(channels) == 0 {
+				cmd.Println("No channels")
+				return nil
+			}
+			for _, channel := range channels {
+				cmd.Printf("%s/%s %s %s -> %s/%s Version: %s\n",
+					channel.PortId,
+					channel.ChannelId,
+					channel.State,
+					channel.Ordering,
+					channel.Counterparty.PortId,
+					channel.Counterparty.ChannelId,
+					channel.Version,
+				)
+			}
+
+			return nil
+		},
+	}
+}
+
+// parseOrdering parses ordered or unordered (or the ORDER_ enum names)
+func parseOrdering(ordering string) (channeltypes.Order, error) {
+	switch strings.TrimPrefix(strings.ToUpper(ordering), "ORDER_") {
+	case "ORDERED":
+		return channeltypes.ORDERED, nil
+	case "UNORDERED":
+		return channeltypes.UNORDERED, nil
+	default:
+		return channeltypes.NONE, fmt.Errorf("invalid channel ordering %q, expected ordered or unordered", ordering)
+	}
+}
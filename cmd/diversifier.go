@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagDiversifier = "diversifier"
+)
+
+func RotateDiversifierCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-diversifier --chain-name [chain-name]",
+		Short: "Rotate the diversifier for a chain, invalidating any signatures that were produced but never submitted",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			chainName := getChainName(cmd)
+			cdc := utils.SetupCodec()
+
+			newDiversifier, err := cmd.Flags().GetString(flagDiversifier)
+			if err != nil {
+				return err
+			}
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			diversifier, err := sm.RotateDiversifier(chainName, newDiversifier)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("New diversifier:", diversifier)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagDiversifier, "", "The new diversifier (a random one is generated if not set)")
+
+	return cmd
+}
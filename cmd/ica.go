@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"os"
+)
+
+func ICACmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ica",
+		Short: "Control interchain accounts on the chain",
+		Long: `Control interchain accounts on the chain.
+Each owner gets its own icacontroller-<owner> port and channel to the interchain accounts host on the chain, and its own account there.`,
+	}
+
+	cmd.AddCommand(icaRegisterCmd())
+	cmd.AddCommand(icaSendCmd())
+
+	return cmd
+}
+
+func icaRegisterCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "register [owner] --chain-name [chain-name]",
+		Short: "Register an interchain account for the owner (or resume the registration) and show its address on the chain",
+		Long: `Register an interchain account for the owner (or resume the registration) and show its address on the chain.
+On an ordered channel (the default) the messages are executed in the order they are sent, but a timeout closes the channel.
+Running it again after that opens a new channel to the same account, which needs the same ordering.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			orderingStr, err := cmd.Flags().GetString(flagOrdering)
+			if err != nil {
+				return err
+			}
+			order, err := parseOrdering(orderingStr)
+			if err != nil {
+				return err
+			}
+
+			sm, err := icaSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			address, err := sm.RegisterInterchainAccount(getChainName(cmd), args[0], order)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(address)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagOrdering, "ordered", "Channel ordering (ordered or unordered)")
+
+	return cmd
+}
+
+func icaSendCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "send [owner] [msgs-file] --chain-name [chain-name]",
+		Short: "Execute messages with the interchain account of the owner",
+		Long: `Execute messages with the interchain account of the owner.
+The file has a message, or a list of messages, in proto JSON with their @type (e.g. /cosmos.bank.v1beta1.MsgSend).
+The messages are executed in a single tx on the chain, either all of them succeed or none of them do.
+On an ordered channel the chain only executes the packet after the packets sent before it, if one of them is still waiting
+for acknowledgement (e.g. after a failed relay), relay flush relays them all in order.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			chainName := getChainName(cmd)
+			owner := args[0]
+
+			bz, err := os.ReadFile(args[1])
+			if err != nil {
+				return err
+			}
+			msgs, err := parseInterchainAccountMsgs(utils.SetupCodec(), bz)
+			if err != nil {
+				return err
+			}
+
+			options, err := getInterchainAccountTxOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			sm, err := icaSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			ack, err := sm.SendInterchainAccountTx(chainName, owner, msgs, options)
+			if err != nil {
+				return err
+			}
+			if !ack.Success() {
+				return fmt.Errorf("the chain rejected the messages: %s", ack.GetError())
+			}
+
+			var txMsgData sdk.TxMsgData
+			if err := proto.Unmarshal(ack.GetResult(), &txMsgData); err != nil {
+				return fmt.Errorf("cannot unmarshal the result of the messages: %w", err)
+			}
+			for _, msgResponse := range txMsgData.MsgResponses {
+				cmd.Println(msgResponse.TypeUrl)
+			}
+			logger.Info("Messages executed by the interchain account", zap.String("chain", chainName), zap.String("owner", owner), zap.Int("messages", len(msgs)))
+
+			return nil
+		},
+	}
+
+	addPacketTimeoutFlags(cmd)
+	cmd.Flags().String(flagMemo, "", "Memo of the interchain account packet")
+
+	return cmd
+}
+
+func getInterchainAccountTxOptions(cmd *cobra.Command) (solomachine.InterchainAccountTxOptions, error) {
+	var options solomachine.InterchainAccountTxOptions
+	var err error
+
+	options.PacketTimeoutOptions, err = getPacketTimeoutOptions(cmd)
+	if err != nil {
+		return solomachine.InterchainAccountTxOptions{}, err
+	}
+	options.Memo, err = cmd.Flags().GetString(flagMemo)
+	if err != nil {
+		return solomachine.InterchainAccountTxOptions{}, err
+	}
+
+	return options, nil
+}
+
+// parseInterchainAccountMsgs reads a single message or a list of messages in proto JSON, the same way as the ibc-go generate-packet-data command
+func parseInterchainAccountMsgs(cdc codec.Codec, bz []byte) ([]proto.Message, error) {
+	var msg sdk.Msg
+	if err := cdc.UnmarshalInterfaceJSON(bz, &msg); err == nil {
+		return []proto.Message{msg}, nil
+	}
+
+	var rawMsgs []json.RawMessage
+	if err := json.Unmarshal(bz, &rawMsgs); err != nil {
+		return nil, fmt.Errorf("expected a message or a list of messages: %w", err)
+	}
+	if len(rawMsgs) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+
+	msgs := make([]proto.Message, len(rawMsgs))
+	for i, rawMsg := range rawMsgs {
+		if err := cdc.UnmarshalInterfaceJSON(rawMsg, &msg); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		msgs[i] = msg
+	}
+
+	return msgs, nil
+}
+
+func icaSoloMachine(cmd *cobra.Command) (*solomachine.SoloMachine, error) {
+	logger := getLogger(cmd)
+	homedir := getHomedir(cmd)
+	config := getConfig(cmd)
+	cdc := utils.SetupCodec()
+
+	r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+	if err != nil {
+		return nil, err
+	}
+
+	return solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+}
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/gjermundgaraba/solo-machine/relayer"
 	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
 	"github.com/gjermundgaraba/solo-machine/utils"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
+const (
+	flagKeyAlgo     = "key-algo"
+	flagSeparateKey = "separate-key"
+)
+
 func InitCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
@@ -19,18 +26,46 @@ func InitCmd() *cobra.Command {
 			chainName := getChainName(cmd)
 			cdc := utils.SetupCodec()
 
+			keyAlgo, err := cmd.Flags().GetString(flagKeyAlgo)
+			if err != nil {
+				return err
+			}
+			separateKey, err := cmd.Flags().GetBool(flagSeparateKey)
+			if err != nil {
+				return err
+			}
+			if keyAlgo != "" && !separateKey {
+				if config.Signer.Type != "" && config.Signer.Type != signer.TypeLocal {
+					return fmt.Errorf("--%s can only be used with the local signer", flagKeyAlgo)
+				}
+				config.Signer.KeyAlgorithm = keyAlgo
+				if err := config.Signer.Validate(); err != nil {
+					return err
+				}
+			}
+
 			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
 			if err != nil {
 				return err
 			}
 
-			sm := solomachine.NewSoloMachine(logger, cdc, r, homedir)
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
 			if !sm.CounterpartyLightClientExists(chainName) {
+				if separateKey && !sm.HasChainKey(chainName) {
+					if err := sm.CreateChainKey(chainName, keyAlgo); err != nil {
+						return err
+					}
+				}
 				if err := sm.CreateCounterpartyLightClient(chainName); err != nil {
 					return err
 				}
 				logger.Info("Counterparty light client created", zap.String("chain", chainName))
-			} else {
+			} else if separateKey && !sm.HasChainKey(chainName) {
+				return fmt.Errorf("the counterparty light client for %s already exists, use keys rotate --%s to move it to its own key", chainName, flagSeparateKey)
+			} else if !sm.SignsOffline() {
 				logger.Info("Counterparty light client already exists", zap.String("chain", chainName))
 				if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
 					return err
@@ -49,6 +84,11 @@ func InitCmd() *cobra.Command {
 				}
 			}
 
+			if sm.SignsOffline() {
+				logger.Info("The signer is offline, use the offline export connection and offline export channel commands to finish the setup", zap.String("chain", chainName))
+				return nil
+			}
+
 			// Connection and channel creation is safe to call multiple times as it checks if it exists and the states
 			// It will also continue if the handshake is started but not completed
 			if err := sm.CreateConnection(chainName); err != nil {
@@ -63,5 +103,8 @@ func InitCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().String(flagKeyAlgo, "", fmt.Sprintf("Key algorithm for the solo machine key (or the chain key with --%s), if it has not been created yet (one of %v, default %s)", flagSeparateKey, signer.SupportedKeyAlgos, signer.DefaultKeyAlgo))
+	cmd.Flags().Bool(flagSeparateKey, false, "Create a separate key for the chain, instead of using the shared solo machine key")
+
 	return cmd
 }
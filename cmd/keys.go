@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/client/input"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"os"
+	"slices"
+	"strings"
+)
+
+const (
+	flagImportKeyFile     = "import-key-file"
+	flagNewPassphraseFile = "new-passphrase-file"
+)
+
+func KeysSoloMachineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "solo-machine",
+		Short: "Manage the solo machine (local signer) key",
+	}
+
+	cmd.AddCommand(keysChangePassphraseCmd())
+	cmd.AddCommand(keysExportCmd())
+	cmd.AddCommand(keysRecoverCmd())
+
+	return cmd
+}
+
+func keysChangePassphraseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "change-passphrase",
+		Short: "Change the passphrase the solo machine key is encrypted with",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			// Unlocks the key with the current passphrase
+			sm, err := solomachine.NewSoloMachine(logger, cdc, nil, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			newPassphraseFile, err := cmd.Flags().GetString(flagNewPassphraseFile)
+			if err != nil {
+				return err
+			}
+
+			var newPassphrase string
+			if newPassphraseFile != "" {
+				bz, err := os.ReadFile(newPassphraseFile)
+				if err != nil {
+					return err
+				}
+				newPassphrase = strings.TrimRight(string(bz), "\r\n")
+			} else {
+				newPassphrase, err = signer.PromptPassphrase("Enter new passphrase for the solo machine key:", true)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := sm.ChangeLocalKeyPassphrase(newPassphrase); err != nil {
+				return err
+			}
+
+			logger.Info("Changed the solo machine key passphrase, remember to update the passphrase file or environment variable if you use them")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagNewPassphraseFile, "", "Read the new passphrase from this file instead of a prompt")
+
+	return cmd
+}
+
+func keysExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [backup-file]",
+		Short: "Export the solo machine key and chain state to a backup file",
+		Long: `Export the solo machine key and chain state to a backup file.
+
+The backup contains the mnemonic of the key (or the private key, for keys that were not created from a mnemonic),
+the diversifiers, identifiers, channels (with their packets, receipts and acknowledgements) and own keys of all the chains in the config file,
+and the ledger: the balances of all the accounts (escrow accounts included) and the denom traces.
+It is NOT encrypted, keep it somewhere safe. Use keys solo-machine recover to rebuild the solo machine from it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, nil, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			var chainNames []string
+			for chainName := range config.Chains {
+				chainNames = append(chainNames, chainName)
+			}
+			slices.Sort(chainNames)
+
+			backup, err := sm.ExportBackup(chainNames)
+			if err != nil {
+				return err
+			}
+
+			if err := solomachine.WriteBackupFile(args[0], backup); err != nil {
+				return err
+			}
+
+			logger.Info("Exported the solo machine backup, it contains the key unencrypted", zap.String("file", args[0]))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func keysRecoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover [backup-file]",
+		Short: "Recover the solo machine key (and chain state) in a fresh home directory",
+		Long: `Recover the solo machine key (and chain state) in a fresh home directory.
+
+With a backup file from keys solo-machine export, the key, the state of the chains and the ledger are restored.
+Run init afterwards to create the light clients again, the existing connections and channels are kept.
+
+Without a backup file, only the key is recovered from a mnemonic (prompted for), using the --key-algo key algorithm.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			keyAlgo, err := cmd.Flags().GetString(flagKeyAlgo)
+			if err != nil {
+				return err
+			}
+
+			var backup *solomachine.Backup
+			if len(args) == 1 {
+				if keyAlgo != "" {
+					return fmt.Errorf("--%s cannot be used with a backup file, the backup has the key algorithm", flagKeyAlgo)
+				}
+				backup, err = solomachine.ReadBackupFile(args[0])
+				if err != nil {
+					return err
+				}
+			} else {
+				mnemonic, err := input.GetString("Enter the mnemonic of the solo machine key:", bufio.NewReader(cmd.InOrStdin()))
+				if err != nil {
+					return err
+				}
+				backup = &solomachine.Backup{
+					KeyBackup: solomachine.KeyBackup{
+						KeyAlgorithm: keyAlgo,
+						Mnemonic:     mnemonic,
+					},
+				}
+			}
+
+			return solomachine.Recover(logger, cdc, homedir, config.Signer, backup)
+		},
+	}
+
+	cmd.Flags().String(flagKeyAlgo, "", fmt.Sprintf("Key algorithm of the key when recovering from a mnemonic (one of %v, default %s)", signer.SupportedKeyAlgos, signer.DefaultKeyAlgo))
+
+	return cmd
+}
+
+func KeysRotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the solo machine (local signer) key on all the chains with a solo machine client",
+		Long: `Rotate the solo machine (local signer) key on all the chains with a solo machine client.
+
+A new key is generated (or imported with --import-key-file) and installed on the solo machine client on every chain,
+with a header signed by the current key. A chain is signed with the new key as soon as its client has it, and the new key
+only replaces the current key once all the chains have confirmed it. If the rotation fails for a chain, run the command again
+to continue with the same new key. Chains with their own key are skipped. A chain with a client that has been removed from
+the config file makes the rotation fail, since it would be left without a usable key: add it back to the config first.
+
+With --separate-key --chain-name [chain-name], only that chain is rotated, to a new key of its own (also if it uses the shared key now).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			var newPrivKey cryptotypes.PrivKey
+			importKeyFile, err := cmd.Flags().GetString(flagImportKeyFile)
+			if err != nil {
+				return err
+			}
+			if importKeyFile != "" {
+				bz, err := os.ReadFile(importKeyFile)
+				if err != nil {
+					return err
+				}
+				if err := cdc.UnmarshalInterfaceJSON(bz, &newPrivKey); err != nil {
+					return err
+				}
+			}
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			separateKey, err := cmd.Flags().GetBool(flagSeparateKey)
+			if err != nil {
+				return err
+			}
+			if separateKey {
+				return sm.RotateChainKey(getChainName(cmd), newPrivKey)
+			}
+
+			var chainNames []string
+			for chainName := range config.Chains {
+				chainNames = append(chainNames, chainName)
+			}
+			slices.Sort(chainNames)
+
+			return sm.RotateKey(chainNames, newPrivKey)
+		},
+	}
+
+	cmd.Flags().String(flagImportKeyFile, "", "Rotate to the private key in this JSON key file (same format as the file signer) instead of generating a new one")
+	cmd.Flags().Bool(flagSeparateKey, false, "Rotate only the --chain-name chain, to a key of its own")
+
+	return cmd
+}
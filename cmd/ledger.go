@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+)
+
+func LedgerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ledger",
+		Short: "Manage the local accounts and balances of the solo machine",
+		Long: `Manage the local accounts and balances of the solo machine.
+Transfers take the tokens from the local account of the sender, and received tokens are given to the local account of the receiver.
+Minting and burning are NOT restricted: there is no admin account or other authorization, so whoever operates the solo machine
+(anyone who can run it against its home directory) can create or destroy any amount of any denom in any account, escrow accounts included.`,
+	}
+
+	cmd.AddCommand(ledgerMintCmd())
+	cmd.AddCommand(ledgerBurnCmd())
+	cmd.AddCommand(ledgerBalancesCmd())
+	cmd.AddCommand(ledgerDenomTracesCmd())
+
+	return cmd
+}
+
+func ledgerMintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mint [account] [amount]",
+		Short: "Create tokens in a local account (e.g. mint alice 1000stake)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			coin, err := sdk.ParseCoinNormalized(args[1])
+			if err != nil {
+				return err
+			}
+
+			sm, err := localSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			return sm.Mint(args[0], coin)
+		},
+	}
+}
+
+func ledgerBurnCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "burn [account] [amount]",
+		Short: "Destroy tokens in a local account (e.g. burn alice 1000stake)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			coin, err := sdk.ParseCoinNormalized(args[1])
+			if err != nil {
+				return err
+			}
+
+			sm, err := localSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			return sm.Burn(args[0], coin)
+		},
+	}
+}
+
+func ledgerBalancesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "balances [account]",
+		Short: "Show the balances of a local account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sm, err := localSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			balances, err := sm.Balances(args[0])
+			if err != nil {
+				return err
+			}
+
+			if balances.Empty() {
+				cmd.Println("No balances")
+				return nil
+			}
+			for _, coin := range balances {
+				if denomTrace, err := sm.DenomTrace(coin.Denom); err == nil {
+					cmd.Printf("%s %s (%s)\n", coin.Amount, coin.Denom, denomTrace.GetFullDenomPath())
+					continue
+				}
+				cmd.Printf("%s %s\n", coin.Amount, coin.Denom)
+			}
+
+			return nil
+		},
+	}
+}
+
+func ledgerDenomTracesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "denom-traces [ibc-denom]",
+		Short: "Show the denom traces of the vouchers received over ICS20 (all of them, or the one of an ibc/<hash> denom)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sm, err := localSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+
+			var denomTraces transfertypes.Traces
+			if len(args) == 1 {
+				denomTrace, err := sm.DenomTrace(args[0])
+				if err != nil {
+					return err
+				}
+				denomTraces = append(denomTraces, denomTrace)
+			} else {
+				denomTraces = sm.DenomTraces()
+			}
+
+			if len(denomTraces) == 0 {
+				cmd.Println("No denom traces")
+				return nil
+			}
+			for _, denomTrace := range denomTraces {
+				cmd.Printf("Denom: %s Path: %s BaseDenom: %s\n", denomTrace.IBCDenom(), denomTrace.Path, denomTrace.BaseDenom)
+			}
+
+			return nil
+		},
+	}
+}
+
+// localSoloMachine sets up the solo machine without a relayer, for the commands that only use the local state
+func localSoloMachine(cmd *cobra.Command) (*solomachine.SoloMachine, error) {
+	logger := getLogger(cmd)
+	homedir := getHomedir(cmd)
+	config := getConfig(cmd)
+	cdc := utils.SetupCodec()
+
+	return solomachine.NewSoloMachine(logger, cdc, nil, homedir, config.Signer, config.StateMachine)
+}
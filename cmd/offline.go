@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func OfflineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "offline",
+		Short: "Sign solo machine headers and proofs with a key on an offline (air-gapped) machine",
+		Long: `Sign solo machine headers and proofs with a key on an offline (air-gapped) machine.
+
+The online machine uses the "offline" signer type with the public key of the offline signer (see the pubkey command).
+1. Export the sign bytes for an operation to a file on the online machine (offline export ...)
+2. Sign the file on the offline machine, using its configured signer (offline sign [file])
+3. Import the signed file on the online machine to finish the operation (offline import [file])`,
+	}
+
+	cmd.AddCommand(offlineExportCmd())
+	cmd.AddCommand(offlineSignCmd())
+	cmd.AddCommand(offlineImportCmd())
+	cmd.AddCommand(offlinePubKeyCmd())
+
+	return cmd
+}
+
+func offlineExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the sign bytes for an operation to a file that can be signed offline",
+	}
+
+	cmd.AddCommand(offlineExportOperationCmd(
+		"update [file] --chain-name [chain-name]",
+		"Export the sign bytes for a counterparty light client update",
+		cobra.ExactArgs(1),
+		func(cmd *cobra.Command, sm *solomachine.SoloMachine, chainName string, args []string) (*solomachine.OfflineSigningFile, error) {
+			return sm.ExportOfflineUpdate(chainName)
+		},
+	))
+	transferCmd := offlineExportOperationCmd(
+		"transfer [sender] [receiver] [amount] [file] --chain-name [chain-name]",
+		"Export the sign bytes for an ICS20 transfer from solo machine to chain",
+		cobra.ExactArgs(4),
+		func(cmd *cobra.Command, sm *solomachine.SoloMachine, chainName string, args []string) (*solomachine.OfflineSigningFile, error) {
+			coin, err := sdk.ParseCoinNormalized(args[2])
+			if err != nil {
+				return nil, err
+			}
+			options, err := getTransferOptions(cmd)
+			if err != nil {
+				return nil, err
+			}
+			return sm.ExportOfflineTransfer(chainName, args[0], args[1], coin.Denom, coin.Amount.Uint64(), options)
+		},
+	)
+	addTransferOptionsFlags(transferCmd)
+	cmd.AddCommand(transferCmd)
+	cmd.AddCommand(offlineExportOperationCmd(
+		"connection [file] --chain-name [chain-name]",
+		"Initialize the connection and export the sign bytes for the connection open ack proofs",
+		cobra.ExactArgs(1),
+		func(cmd *cobra.Command, sm *solomachine.SoloMachine, chainName string, args []string) (*solomachine.OfflineSigningFile, error) {
+			return sm.ExportOfflineConnectionOpenAck(chainName)
+		},
+	))
+	cmd.AddCommand(offlineExportOperationCmd(
+		"channel [file] --chain-name [chain-name]",
+		"Initialize the ICS20 channel and export the sign bytes for the channel open ack proof",
+		cobra.ExactArgs(1),
+		func(cmd *cobra.Command, sm *solomachine.SoloMachine, chainName string, args []string) (*solomachine.OfflineSigningFile, error) {
+			return sm.ExportOfflineChannelOpenAck(chainName)
+		},
+	))
+
+	return cmd
+}
+
+// offlineExportOperationCmd creates an export command, the last argument is always the file to export to
+func offlineExportOperationCmd(
+	use string,
+	short string,
+	args cobra.PositionalArgs,
+	export func(cmd *cobra.Command, sm *solomachine.SoloMachine, chainName string, args []string) (*solomachine.OfflineSigningFile, error),
+) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			chainName := getChainName(cmd)
+			cdc := utils.SetupCodec()
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			file, err := export(cmd, sm, chainName, args)
+			if err != nil {
+				return err
+			}
+
+			path := args[len(args)-1]
+			if err := solomachine.WriteOfflineSigningFile(file, path); err != nil {
+				return err
+			}
+
+			logger.Info("Exported sign bytes for offline signing", zap.String("operation", file.Operation), zap.Int("entries", len(file.Entries)), zap.String("file", path))
+
+			return nil
+		},
+	}
+}
+
+func offlineSignCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sign [file]",
+		Short: "Sign an exported file with the configured signer (does not need network access)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			// No relayer, signing is done without any connection to the chains
+			sm, err := solomachine.NewSoloMachine(logger, cdc, nil, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			path := args[0]
+			file, err := solomachine.ReadOfflineSigningFile(path)
+			if err != nil {
+				return err
+			}
+
+			if err := sm.SignOffline(file); err != nil {
+				return err
+			}
+
+			if err := solomachine.WriteOfflineSigningFile(file, path); err != nil {
+				return err
+			}
+
+			logger.Info("Signed offline signing file", zap.String("operation", file.Operation), zap.Int("entries", len(file.Entries)), zap.String("file", path))
+
+			return nil
+		},
+	}
+}
+
+func offlineImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import the signatures from a signed file and finish the operation it was exported for",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			file, err := solomachine.ReadOfflineSigningFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			if err := sm.ImportOffline(file); err != nil {
+				return err
+			}
+
+			logger.Info("Imported offline signatures", zap.String("chain", file.ChainName), zap.String("operation", file.Operation))
+
+			return nil
+		},
+	}
+}
+
+func offlinePubKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pubkey",
+		Short: "Print the public key of the configured signer, to be used as public-key for the offline signer on the online machine",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			cdc := utils.SetupCodec()
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, nil, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			bz, err := cdc.MarshalInterfaceJSON(sm.PublicKey())
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(string(bz))
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+const flagAll = "all"
+
+func PacketsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "packets",
+		Short: "Inspect the packets the solo machine has sent on the ICS20 channel",
+	}
+
+	cmd.AddCommand(packetsListCmd())
+	cmd.AddCommand(packetsShowCmd())
+
+	return cmd
+}
+
+func packetsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list --chain-name [chain-name]",
+		Short: "List the packets waiting for an acknowledgement (or all sent packets with --all)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool(flagAll)
+			if err != nil {
+				return err
+			}
+
+			sm, err := packetsSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+			infos, err := sm.SentPackets(getChainName(cmd), all)
+			if err != nil {
+				return err
+			}
+
+			if len(infos) == 0 {
+				cmd.Println("No packets")
+				return nil
+			}
+			for _, info := range infos {
+				cmd.Printf("Sequence: %d State: %s Timeout: %s ChainReceipt: %t Acknowledgement: %s\n",
+					info.Packet.Sequence, info.State, packetTimeout(info), info.ChainReceipt, packetAcknowledgement(info))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool(flagAll, false, "Include acknowledged and timed out packets")
+
+	return cmd
+}
+
+func packetsShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [sequence] --chain-name [chain-name]",
+		Short: "Show a sent packet, its commitment, timeout, receipt on the chain and acknowledgement",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sequence, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid sequence: %w", err)
+			}
+
+			sm, err := packetsSoloMachine(cmd)
+			if err != nil {
+				return err
+			}
+			info, err := sm.SentPacket(getChainName(cmd), sequence)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("Sequence:", info.Packet.Sequence)
+			cmd.Println("State:", info.State)
+			cmd.Println("Source:", info.Packet.SourcePort+"/"+info.Packet.SourceChannel)
+			cmd.Println("Destination:", info.Packet.DestinationPort+"/"+info.Packet.DestinationChannel)
+			cmd.Println("Data:", string(info.Packet.Data))
+			cmd.Println("Commitment:", hex.EncodeToString(info.Commitment))
+			cmd.Println("Timeout:", packetTimeout(info))
+			cmd.Println("ChainReceipt:", info.ChainReceipt)
+			cmd.Println("Acknowledgement:", packetAcknowledgement(info))
+
+			return nil
+		},
+	}
+}
+
+func packetsSoloMachine(cmd *cobra.Command) (*solomachine.SoloMachine, error) {
+	logger := getLogger(cmd)
+	homedir := getHomedir(cmd)
+	config := getConfig(cmd)
+	cdc := utils.SetupCodec()
+
+	r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+	if err != nil {
+		return nil, err
+	}
+
+	return solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+}
+
+func packetTimeout(info solomachine.PacketInfo) string {
+	timeout := "height " + info.Packet.TimeoutHeight.String()
+	if info.Packet.TimeoutTimestamp != 0 {
+		timeout += fmt.Sprintf(", timestamp %d", info.Packet.TimeoutTimestamp)
+	}
+
+	return timeout
+}
+
+func packetAcknowledgement(info solomachine.PacketInfo) string {
+	switch {
+	case info.RawAcknowledgement == nil:
+		return "none"
+	case info.Acknowledgement == nil:
+		return "raw: " + hex.EncodeToString(info.RawAcknowledgement)
+	case info.Acknowledgement.Success():
+		return "success"
+	default:
+		return "error: " + info.Acknowledgement.GetError()
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+)
+
+func RelayCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "relay",
+		Short: "Relay packets between the chain and the solo machine",
+	}
+
+	cmd.AddCommand(relayOperationCmd(
+		"receive --chain-name [chain-name]",
+		"Receive the packets sent from the chain on the open channels and relay the acknowledgements back",
+		`Receive the packets sent from the chain on the open channels and relay the acknowledgements back.
+
+The packets are found from the send_packet events on the chain, so the tx indexer needs to be enabled on the node.
+On an ordered channel the packets are received in order, and a packet that timed out is timed out on the chain, which closes the channel.
+Only a timeout height can be proven to the chain: the solo machine timestamps are in milliseconds while packet timeout timestamps are in nanoseconds,
+so a packet that timed out by its timeout timestamp stays on the chain and the ordered channel does not receive any more packets.`,
+		func(sm *solomachine.SoloMachine, chainName string) error {
+			_, err := sm.ReceivePackets(chainName)
+			return err
+		},
+	))
+	cmd.AddCommand(relayOperationCmd(
+		"timeouts --chain-name [chain-name]",
+		"Time out the packets sent by the solo machine that the chain did not receive in time",
+		`Time out the packets sent by the solo machine that the chain did not receive in time.
+
+The absence of a packet receipt on the chain is verified against the light client before the packet times out (and e.g. the transfer is reversed).
+All packets waiting for an acknowledgement time out if the channel has been closed on the chain.
+On an ordered channel the next receive sequence on the chain is verified instead, and a timeout closes the channel: the packets after it time out too,
+and the channel is closed on the chain with a channel close confirm (which is retried on every run until the chain has closed it).
+Packets the chain did receive are acknowledged instead (the acknowledgement is found from the events, so the tx indexer needs to be enabled on the node).`,
+		func(sm *solomachine.SoloMachine, chainName string) error {
+			_, err := sm.TimeoutPackets(chainName)
+			return err
+		},
+	))
+	cmd.AddCommand(relayOperationCmd(
+		"flush --chain-name [chain-name]",
+		"Relay all the packets and acknowledgements that have not been relayed in either direction",
+		`Relay all the packets and acknowledgements that have not been relayed in either direction.
+
+This receives the packets sent from the chain (like relay receive), and goes through the solo machine packets waiting for acknowledgement:
+packets the chain received are acknowledged, packets that can no longer be received time out (like relay timeouts),
+and the rest are relayed to the chain again, e.g. after an interrupted transfer or a failed tx.`,
+		func(sm *solomachine.SoloMachine, chainName string) error {
+			_, err := sm.Flush(chainName)
+			return err
+		},
+	))
+
+	return cmd
+}
+
+// relayOperationCmd creates a relay command that runs the operation on the solo machine for the chain
+func relayOperationCmd(use string, short string, long string, operation func(sm *solomachine.SoloMachine, chainName string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			chainName := getChainName(cmd)
+			cdc := utils.SetupCodec()
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+
+			return operation(sm, chainName)
+		},
+	}
+}
@@ -115,11 +115,22 @@ func RootCmd() *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(keys.Commands())
+	keysCmd := keys.Commands()
+	keysCmd.AddCommand(KeysRotateCmd())
+	keysCmd.AddCommand(KeysSoloMachineCmd())
+	cmd.AddCommand(keysCmd)
 	cmd.AddCommand(InitCmd())
 	cmd.AddCommand(UpdateCmd())
 	cmd.AddCommand(TransferCmd())
+	cmd.AddCommand(TransferBatchCmd())
+	cmd.AddCommand(RelayCmd())
+	cmd.AddCommand(PacketsCmd())
+	cmd.AddCommand(LedgerCmd())
+	cmd.AddCommand(ChannelsCmd())
+	cmd.AddCommand(ICACmd())
 	cmd.AddCommand(StatusCmd())
+	cmd.AddCommand(OfflineCmd())
+	cmd.AddCommand(RotateDiversifierCmd())
 
 	userHomeDir, err := os.UserHomeDir()
 	if err != nil {
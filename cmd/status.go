@@ -23,7 +23,10 @@ func StatusCmd() *cobra.Command {
 				return err
 			}
 
-			sm := solomachine.NewSoloMachine(logger, cdc, r, homedir)
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
 			status, err := sm.Status(chainName)
 			if err != nil {
 				return err
@@ -31,6 +34,8 @@ func StatusCmd() *cobra.Command {
 
 			cmd.Println("Status:")
 			cmd.Println("Diversifier:", status.Diversifier)
+			cmd.Println("PublicKey:", status.PublicKey)
+			cmd.Println("SeparateKey:", status.SeparateKey)
 			cmd.Println("LightClientID:", status.LightClientID)
 			cmd.Println("LightClientLatestHeight:", status.LightClientLatestHeight)
 			cmd.Println("CounterpartyActualHeight:", status.CounterpartyActualHeight)
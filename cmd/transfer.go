@@ -1,17 +1,28 @@
 package cmd
 
 import (
+	"fmt"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	"github.com/gjermundgaraba/solo-machine/relayer"
 	"github.com/gjermundgaraba/solo-machine/solomachine"
 	"github.com/gjermundgaraba/solo-machine/utils"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	flagTimeoutHeightOffset    = "timeout-height-offset"
+	flagTimeoutTimestampOffset = "timeout-timestamp-offset"
+	flagTimeoutHeight          = "timeout-height"
+	flagTimeoutTimestamp       = "timeout-timestamp"
+	flagMemo                   = "memo"
 )
 
 func TransferCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "transfer [sender] [receiver] [amount] --chain-name [chain-name]",
-		Short: "Transfer (more like create, honestly) tokens from solo machine to chain over ICS20 channel",
+		Short: "Transfer tokens from solo machine to chain over ICS20 channel, debiting the sender on the ledger (fails if the sender does not have the funds)",
 		Args:  cobra.ExactArgs(3),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			logger := getLogger(cmd)
@@ -28,17 +39,97 @@ func TransferCmd() *cobra.Command {
 				return err
 			}
 
+			options, err := getTransferOptions(cmd)
+			if err != nil {
+				return err
+			}
+
 			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
 			if err != nil {
 				return err
 			}
 
-			sm := solomachine.NewSoloMachine(logger, cdc, r, homedir)
-			if err := sm.Transfer(chainName, sender, receiver, coin.Denom, coin.Amount.Uint64()); err != nil {
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+			ack, err := sm.Transfer(chainName, sender, receiver, coin.Denom, coin.Amount.Uint64(), options)
+			if err != nil {
 				return err
 			}
+			if !ack.Success() {
+				return fmt.Errorf("the chain rejected the transfer: %s", ack.GetError())
+			}
+
+			logger.Info("Transfer accepted by the chain", zap.String("chain", chainName), zap.String("receiver", receiver), zap.String("amount", coin.String()))
 
 			return nil
 		},
 	}
+
+	addTransferOptionsFlags(cmd)
+
+	return cmd
+}
+
+func addTransferOptionsFlags(cmd *cobra.Command) {
+	addPacketTimeoutFlags(cmd)
+	cmd.Flags().String(flagMemo, "", "Memo of the packet, e.g. for packet-forward-middleware or wasm hooks on the receiving chain")
+}
+
+func addPacketTimeoutFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64(flagTimeoutHeightOffset, solomachine.DefaultTimeoutHeightOffset, "Timeout height offset in blocks from the latest chain height (0 disables the timeout height)")
+	cmd.Flags().Duration(flagTimeoutTimestampOffset, 0, "Timeout timestamp offset from the latest chain time, e.g. 10m (0 disables the timeout timestamp)")
+	cmd.Flags().String(flagTimeoutHeight, "", "Absolute timeout height in the format {revision}-{height}, overrides the timeout height offset")
+	cmd.Flags().Uint64(flagTimeoutTimestamp, 0, "Absolute timeout timestamp in unix nanoseconds, overrides the timeout timestamp offset")
+}
+
+func getTransferOptions(cmd *cobra.Command) (solomachine.TransferOptions, error) {
+	var options solomachine.TransferOptions
+	var err error
+
+	options.PacketTimeoutOptions, err = getPacketTimeoutOptions(cmd)
+	if err != nil {
+		return solomachine.TransferOptions{}, err
+	}
+	options.Memo, err = cmd.Flags().GetString(flagMemo)
+	if err != nil {
+		return solomachine.TransferOptions{}, err
+	}
+
+	return options, nil
+}
+
+func getPacketTimeoutOptions(cmd *cobra.Command) (solomachine.PacketTimeoutOptions, error) {
+	var options solomachine.PacketTimeoutOptions
+	var err error
+
+	options.TimeoutHeightOffset, err = cmd.Flags().GetUint64(flagTimeoutHeightOffset)
+	if err != nil {
+		return solomachine.PacketTimeoutOptions{}, err
+	}
+	options.TimeoutTimestampOffset, err = cmd.Flags().GetDuration(flagTimeoutTimestampOffset)
+	if err != nil {
+		return solomachine.PacketTimeoutOptions{}, err
+	}
+	if options.TimeoutTimestampOffset < 0 {
+		return solomachine.PacketTimeoutOptions{}, fmt.Errorf("--%s cannot be negative", flagTimeoutTimestampOffset)
+	}
+
+	timeoutHeightStr, err := cmd.Flags().GetString(flagTimeoutHeight)
+	if err != nil {
+		return solomachine.PacketTimeoutOptions{}, err
+	}
+	if timeoutHeightStr != "" {
+		options.TimeoutHeight, err = clienttypes.ParseHeight(timeoutHeightStr)
+		if err != nil {
+			return solomachine.PacketTimeoutOptions{}, fmt.Errorf("invalid --%s: %w", flagTimeoutHeight, err)
+		}
+	}
+	options.TimeoutTimestamp, err = cmd.Flags().GetUint64(flagTimeoutTimestamp)
+	if err != nil {
+		return solomachine.PacketTimeoutOptions{}, err
+	}
+
+	return options, nil
 }
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const (
+	flagBatchSize = "batch-size"
+	flagRunID     = "run-id"
+)
+
+func TransferBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer-batch [sender] [file] --chain-name [chain-name]",
+		Short: "Transfer tokens from solo machine to many receivers on the chain, with many packets in each tx",
+		Long: `Transfer tokens from solo machine to many receivers on the chain, with many packets in each tx.
+
+The file is either a JSON file with a list of {"receiver": ..., "denom": ..., "amount": ...} objects,
+or a CSV file with receiver,denom,amount rows (with an optional header row).
+
+Progress is stored for the combination of sender, run id and file content, so running the same command again after an interruption
+resumes the batch without sending any row twice. A batch that has been completed is not sent again, to send the same file again
+(e.g. a second airdrop) give it a new --run-id. The transfer options apply to all the packets sent in the run.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := getLogger(cmd)
+			homedir := getHomedir(cmd)
+			config := getConfig(cmd)
+			chainName := getChainName(cmd)
+			cdc := utils.SetupCodec()
+
+			sender := args[0]
+			runID, err := cmd.Flags().GetString(flagRunID)
+			if err != nil {
+				return err
+			}
+			batch, err := solomachine.ReadTransferBatchFile(args[1], sender, runID)
+			if err != nil {
+				return err
+			}
+
+			batchSize, err := cmd.Flags().GetInt(flagBatchSize)
+			if err != nil {
+				return err
+			}
+			options, err := getTransferOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			r, err := relayer.NewRelayer(cmd.Context(), logger, cdc, config, homedir)
+			if err != nil {
+				return err
+			}
+
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
+			result, err := sm.TransferBatch(chainName, sender, batch, batchSize, options)
+			if errors.Is(err, solomachine.ErrTransferBatchCompleted) {
+				return fmt.Errorf("%w, use a new --%s to send the same file again", err, flagRunID)
+			}
+			if err != nil {
+				return err
+			}
+
+			logger.Info("Transfer batch done",
+				zap.String("chain", chainName),
+				zap.String("batch-id", batch.ID),
+				zap.Int("accepted", result.Accepted),
+				zap.Int("rejected", result.Rejected),
+				zap.Int("unfinished", result.Unfinished),
+			)
+			if result.Unfinished > 0 {
+				logger.Warn("Some rows are unfinished, run the same command again to retry them (timed out rows are reversed by relay timeouts)")
+			}
+			if result.Rejected > 0 {
+				return fmt.Errorf("the chain rejected %d of the transfers", result.Rejected)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Int(flagBatchSize, solomachine.DefaultTransferBatchSize, "Number of packets relayed in each tx")
+	cmd.Flags().String(flagRunID, "", "Identifies a new run of the same file, e.g. a second airdrop (leave it out, or give the same one, to resume a run)")
+	addTransferOptionsFlags(cmd)
+
+	return cmd
+}
@@ -23,7 +23,10 @@ func UpdateCmd() *cobra.Command {
 				return err
 			}
 
-			sm := solomachine.NewSoloMachine(logger, cdc, r, homedir)
+			sm, err := solomachine.NewSoloMachine(logger, cdc, r, homedir, config.Signer, config.StateMachine)
+			if err != nil {
+				return err
+			}
 			if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
 				return err
 			}
@@ -1,7 +1,6 @@
 package relayer
 
 import (
-	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"go.uber.org/zap"
@@ -23,14 +22,14 @@ func (r *Relayer) QueryChannel(chainName string, portID string, channelID string
 	return res.Channel, nil
 }
 
-func (r *Relayer) InitChannel(chainName string, connectionID string, portID string, version string, counterpartyPortID string) (string, error) {
+func (r *Relayer) InitChannel(chainName string, connectionID string, portID string, version string, order channeltypes.Order, counterpartyPortID string) (string, error) {
 	clientCtx := r.createClientCtx(chainName)
 	txf := r.createTxFactory(clientCtx, chainName)
 
 	initMsg := channeltypes.NewMsgChannelOpenInit(
 		portID,
 		version,
-		channeltypes.UNORDERED,
+		order,
 		[]string{connectionID},
 		counterpartyPortID,
 		clientCtx.From,
@@ -50,15 +49,15 @@ func (r *Relayer) InitChannel(chainName string, connectionID string, portID stri
 	return channelID, nil
 }
 
-func (r *Relayer) ChannelOpenAck(chainName string, channelID string, counterpartyChannelID string, tryProof []byte, proofHeight clienttypes.Height) error {
+func (r *Relayer) ChannelOpenAck(chainName string, portID string, channelID string, counterpartyChannelID string, counterpartyVersion string, tryProof []byte, proofHeight clienttypes.Height) error {
 	clientCtx := r.createClientCtx(chainName)
 	txf := r.createTxFactory(clientCtx, chainName)
 
 	ackMsg := channeltypes.NewMsgChannelOpenAck(
-		transfertypes.PortID,
+		portID,
 		channelID,
 		counterpartyChannelID,
-		transfertypes.Version,
+		counterpartyVersion,
 		tryProof,
 		proofHeight,
 		clientCtx.From,
@@ -67,3 +66,74 @@ func (r *Relayer) ChannelOpenAck(chainName string, channelID string, counterpart
 	_, err := r.sendTx(clientCtx, txf, ackMsg)
 	return err
 }
+
+// ChannelOpenTry answers a channel handshake started by the solo machine, and returns the channel id on the chain
+func (r *Relayer) ChannelOpenTry(chainName string, portID string, order channeltypes.Order, connectionID string, counterpartyPortID string, counterpartyChannelID string, counterpartyVersion string, initProof []byte, proofHeight clienttypes.Height) (string, error) {
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	tryMsg := channeltypes.NewMsgChannelOpenTry(
+		portID,
+		counterpartyVersion,
+		order,
+		[]string{connectionID},
+		counterpartyPortID,
+		counterpartyChannelID,
+		counterpartyVersion,
+		initProof,
+		proofHeight,
+		clientCtx.From,
+	)
+	txResp, err := r.sendTx(clientCtx, txf, tryMsg)
+	if err != nil {
+		return "", err
+	}
+
+	channelID, err := parseChannelIDFromEvents(txResp.Events)
+	if err != nil {
+		return "", err
+	}
+
+	r.logger.Info("Channel open try on the cosmos chain", zap.String("channel-id", channelID))
+
+	return channelID, nil
+}
+
+func (r *Relayer) ChannelOpenConfirm(chainName string, portID string, channelID string, ackProof []byte, proofHeight clienttypes.Height) error {
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	confirmMsg := channeltypes.NewMsgChannelOpenConfirm(
+		portID,
+		channelID,
+		ackProof,
+		proofHeight,
+		clientCtx.From,
+	)
+
+	_, err := r.sendTx(clientCtx, txf, confirmMsg)
+	return err
+}
+
+func (r *Relayer) ChannelCloseConfirm(chainName string, portID string, channelID string, initProof []byte, proofHeight clienttypes.Height) error {
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	closeConfirmMsg := channeltypes.NewMsgChannelCloseConfirm(
+		portID,
+		channelID,
+		initProof,
+		proofHeight,
+		clientCtx.From,
+		0,
+	)
+
+	_, err := r.sendTx(clientCtx, txf, closeConfirmMsg)
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Channel close confirm on the cosmos chain", zap.String("channel-id", channelID))
+
+	return nil
+}
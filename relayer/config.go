@@ -2,6 +2,9 @@ package relayer
 
 import (
 	"fmt"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	"github.com/gjermundgaraba/solo-machine/solomachine/statemachine"
+	"github.com/gjermundgaraba/solo-machine/solomachine/storage"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 	"os"
@@ -10,6 +13,9 @@ import (
 
 type Config struct {
 	Chains map[string]ChainConfig `yaml:"chains"` // map of chain-name to a chain configuration
+	Signer signer.Config          `yaml:"signer"` // the signer used by the solo machine itself
+	// the external state machine the packets are handled by, instead of the built-in ledger
+	StateMachine statemachine.Config `yaml:"state-machine,omitempty"`
 }
 
 const configFileName = "config.yaml"
@@ -30,7 +36,15 @@ type ChainConfig struct {
 }
 
 func (config Config) Validate() error {
+	if err := config.Signer.Validate(); err != nil {
+		return err
+	}
+
 	for chainName, chainConfig := range config.Chains {
+		if err := storage.ValidateChainName(chainName); err != nil {
+			return err
+		}
+
 		if chainConfig.ChainID == "" {
 			return fmt.Errorf("chain ID is required for chain %s", chainName)
 		}
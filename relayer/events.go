@@ -1,12 +1,14 @@
 package relayer
 
 import (
+	"encoding/hex"
 	"fmt"
 	abcitypes "github.com/cometbft/cometbft/abci/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	"slices"
+	"strconv"
 )
 
 // Repurposed from cosmos relayer
@@ -35,3 +37,71 @@ func parseAttributeFromEvents(events []abcitypes.Event, eventTypes []string, att
 
 	return "", fmt.Errorf("attribute not found")
 }
+
+// parseAcknowledgementFromEvents finds the acknowledgement written for the packet in the write_acknowledgement events
+func parseAcknowledgementFromEvents(events []abcitypes.Event, packet channeltypes.Packet) ([]byte, error) {
+	for _, event := range events {
+		if event.Type != channeltypes.EventTypeWriteAck {
+			continue
+		}
+
+		attributes := make(map[string]string)
+		for _, attr := range event.Attributes {
+			attributes[attr.Key] = attr.Value
+		}
+		if attributes[channeltypes.AttributeKeySrcPort] != packet.SourcePort ||
+			attributes[channeltypes.AttributeKeySrcChannel] != packet.SourceChannel ||
+			attributes[channeltypes.AttributeKeySequence] != strconv.FormatUint(packet.Sequence, 10) {
+			continue
+		}
+
+		return hex.DecodeString(attributes[channeltypes.AttributeKeyAckHex])
+	}
+
+	return nil, fmt.Errorf("attribute not found")
+}
+
+// parsePacketsFromEvents parses the packets from all the events of the given type (e.g. send_packet)
+func parsePacketsFromEvents(events []abcitypes.Event, eventType string) ([]channeltypes.Packet, error) {
+	var packets []channeltypes.Packet
+	for _, event := range events {
+		if event.Type != eventType {
+			continue
+		}
+
+		attributes := make(map[string]string)
+		for _, attr := range event.Attributes {
+			attributes[attr.Key] = attr.Value
+		}
+
+		data, err := hex.DecodeString(attributes[channeltypes.AttributeKeyDataHex])
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet data in %s event: %w", eventType, err)
+		}
+		sequence, err := strconv.ParseUint(attributes[channeltypes.AttributeKeySequence], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet sequence in %s event: %w", eventType, err)
+		}
+		timeoutHeight, err := clienttypes.ParseHeight(attributes[channeltypes.AttributeKeyTimeoutHeight])
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet timeout height in %s event: %w", eventType, err)
+		}
+		timeoutTimestamp, err := strconv.ParseUint(attributes[channeltypes.AttributeKeyTimeoutTimestamp], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet timeout timestamp in %s event: %w", eventType, err)
+		}
+
+		packets = append(packets, channeltypes.NewPacket(
+			data,
+			sequence,
+			attributes[channeltypes.AttributeKeySrcPort],
+			attributes[channeltypes.AttributeKeySrcChannel],
+			attributes[channeltypes.AttributeKeyDstPort],
+			attributes[channeltypes.AttributeKeyDstChannel],
+			timeoutHeight,
+			timeoutTimestamp,
+		))
+	}
+
+	return packets, nil
+}
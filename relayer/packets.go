@@ -0,0 +1,175 @@
+package relayer
+
+import (
+	"fmt"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	ibcclient "github.com/cosmos/ibc-go/v8/modules/core/client"
+	"go.uber.org/zap"
+)
+
+const txSearchPerPage = 100
+
+// QuerySendPackets finds all the packets the chain has sent on the channel, from the send_packet events of the txs on the chain
+// It needs the tx indexer to be enabled on the node.
+func (r *Relayer) QuerySendPackets(chainName string, portID string, channelID string) ([]channeltypes.Packet, error) {
+	clientCtx := r.createClientCtx(chainName)
+
+	query := fmt.Sprintf("%s.%s='%s' AND %s.%s='%s'",
+		channeltypes.EventTypeSendPacket, channeltypes.AttributeKeySrcPort, portID,
+		channeltypes.EventTypeSendPacket, channeltypes.AttributeKeySrcChannel, channelID,
+	)
+
+	var packets []channeltypes.Packet
+	page := 1
+	perPage := txSearchPerPage
+	for {
+		res, err := clientCtx.Client.TxSearch(clientCtx.CmdContext, query, false, &page, &perPage, "asc")
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range res.Txs {
+			txPackets, err := parsePacketsFromEvents(tx.TxResult.Events, channeltypes.EventTypeSendPacket)
+			if err != nil {
+				return nil, err
+			}
+			for _, packet := range txPackets {
+				if packet.SourcePort == portID && packet.SourceChannel == channelID {
+					packets = append(packets, packet)
+				}
+			}
+		}
+
+		if page*perPage >= res.TotalCount {
+			break
+		}
+		page++
+	}
+
+	r.logger.Debug("Found sent packets", zap.String("chain", chainName), zap.String("channel-id", channelID), zap.Int("packets", len(packets)))
+
+	return packets, nil
+}
+
+// QueryWriteAcknowledgement finds the acknowledgement the chain wrote for a packet from the solo machine, from the write_acknowledgement event
+func (r *Relayer) QueryWriteAcknowledgement(chainName string, packet channeltypes.Packet) ([]byte, error) {
+	clientCtx := r.createClientCtx(chainName)
+
+	query := fmt.Sprintf("%s.%s='%s' AND %s.%s='%s' AND %s.%s='%d'",
+		channeltypes.EventTypeWriteAck, channeltypes.AttributeKeySrcPort, packet.SourcePort,
+		channeltypes.EventTypeWriteAck, channeltypes.AttributeKeySrcChannel, packet.SourceChannel,
+		channeltypes.EventTypeWriteAck, channeltypes.AttributeKeySequence, packet.Sequence,
+	)
+
+	page := 1
+	perPage := txSearchPerPage
+	res, err := clientCtx.Client.TxSearch(clientCtx.CmdContext, query, false, &page, &perPage, "asc")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range res.Txs {
+		acknowledgement, err := parseAcknowledgementFromEvents(tx.TxResult.Events, packet)
+		if err == nil {
+			return acknowledgement, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no acknowledgement found for packet %d on the chain", packet.Sequence)
+}
+
+// QueryPacketReceipt returns true if the chain has received the packet with the sequence on the channel
+func (r *Relayer) QueryPacketReceipt(chainName string, portID string, channelID string, sequence uint64) (bool, error) {
+	clientCtx := r.createClientCtx(chainName)
+	queryClient := channeltypes.NewQueryClient(clientCtx)
+	req := &channeltypes.QueryPacketReceiptRequest{
+		PortId:    portID,
+		ChannelId: channelID,
+		Sequence:  sequence,
+	}
+
+	res, err := queryClient.PacketReceipt(clientCtx.CmdContext, req)
+	if err != nil {
+		return false, err
+	}
+
+	return res.Received, nil
+}
+
+// QueryNextSequenceReceive returns the sequence of the next packet the chain receives on the ordered channel
+func (r *Relayer) QueryNextSequenceReceive(chainName string, portID string, channelID string) (uint64, error) {
+	clientCtx := r.createClientCtx(chainName)
+	queryClient := channeltypes.NewQueryClient(clientCtx)
+	req := &channeltypes.QueryNextSequenceReceiveRequest{
+		PortId:    portID,
+		ChannelId: channelID,
+	}
+
+	res, err := queryClient.NextSequenceReceive(clientCtx.CmdContext, req)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.NextSequenceReceive, nil
+}
+
+// QueryProof queries the value under the key in the IBC store, with a proof at the given height
+// The value is empty if the key does not exist, in which case the proof is a non-membership proof.
+func (r *Relayer) QueryProof(chainName string, key []byte, height clienttypes.Height) ([]byte, []byte, error) {
+	clientCtx := r.createClientCtx(chainName).WithHeight(int64(height.RevisionHeight))
+
+	value, proof, proofHeight, err := ibcclient.QueryTendermintProof(clientCtx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !proofHeight.EQ(height) {
+		return nil, nil, fmt.Errorf("unexpected proof height: wanted %s, got %s", height, proofHeight)
+	}
+
+	return value, proof, nil
+}
+
+func (r *Relayer) SendMsgAcknowledgement(
+	chainName string,
+	packet channeltypes.Packet,
+	acknowledgement []byte,
+	ackProof []byte,
+	proofHeight clienttypes.Height,
+) error {
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	msg := channeltypes.NewMsgAcknowledgement(
+		packet,
+		acknowledgement,
+		ackProof,
+		proofHeight,
+		clientCtx.From,
+	)
+
+	_, err := r.sendTx(clientCtx, txf, msg)
+	return err
+}
+
+func (r *Relayer) SendMsgTimeout(
+	chainName string,
+	packet channeltypes.Packet,
+	nextSequenceRecv uint64,
+	unreceivedProof []byte,
+	proofHeight clienttypes.Height,
+) error {
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	msg := channeltypes.NewMsgTimeout(
+		packet,
+		nextSequenceRecv,
+		unreceivedProof,
+		proofHeight,
+		clientCtx.From,
+	)
+
+	_, err := r.sendTx(clientCtx, txf, msg)
+	return err
+}
@@ -1,16 +1,20 @@
 package relayer
 
 import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"go.uber.org/zap"
 )
 
+// SendMsgRecvPacket relays a packet from the solo machine and returns the acknowledgement the chain wrote for it
 func (r *Relayer) SendMsgRecvPacket(
 	chainName string,
 	packet channeltypes.Packet,
 	commitmentProof []byte,
 	proofHeight clienttypes.Height,
-) error {
+) ([]byte, error) {
 	clientCtx := r.createClientCtx(chainName)
 	txf := r.createTxFactory(clientCtx, chainName)
 
@@ -21,6 +25,59 @@ func (r *Relayer) SendMsgRecvPacket(
 		clientCtx.From,
 	)
 
-	_, err := r.sendTx(clientCtx, txf, msg)
-	return err
+	res, err := r.sendTx(clientCtx, txf, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledgement, err := parseAcknowledgementFromEvents(res.Events, packet)
+	if err != nil {
+		return nil, fmt.Errorf("no acknowledgement written for packet %d (it might have been received already): %w", packet.Sequence, err)
+	}
+
+	return acknowledgement, nil
+}
+
+// SendMsgRecvPackets relays many packets from the solo machine in a single tx and returns the acknowledgements the chain wrote for them
+// The commitment proofs must be signed with consecutive solo machine sequences, in the order of the packets.
+// The acknowledgement is nil for a packet the chain did not write an acknowledgement for (e.g. because it had been received already).
+func (r *Relayer) SendMsgRecvPackets(
+	chainName string,
+	packets []channeltypes.Packet,
+	commitmentProofs [][]byte,
+	proofHeight clienttypes.Height,
+) ([][]byte, error) {
+	if len(packets) != len(commitmentProofs) {
+		return nil, fmt.Errorf("got %d packets, but %d commitment proofs", len(packets), len(commitmentProofs))
+	}
+
+	clientCtx := r.createClientCtx(chainName)
+	txf := r.createTxFactory(clientCtx, chainName)
+
+	msgs := make([]sdk.Msg, len(packets))
+	for i, packet := range packets {
+		msgs[i] = channeltypes.NewMsgRecvPacket(
+			packet,
+			commitmentProofs[i],
+			proofHeight,
+			clientCtx.From,
+		)
+	}
+
+	res, err := r.sendTx(clientCtx, txf, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledgements := make([][]byte, len(packets))
+	for i, packet := range packets {
+		acknowledgement, err := parseAcknowledgementFromEvents(res.Events, packet)
+		if err != nil {
+			r.logger.Warn("No acknowledgement written for packet", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+			continue
+		}
+		acknowledgements[i] = acknowledgement
+	}
+
+	return acknowledgements, nil
 }
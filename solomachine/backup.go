@@ -0,0 +1,236 @@
+package solomachine
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"go.uber.org/zap"
+	"os"
+)
+
+// Backup contains what is needed to rebuild the solo machine identity in a fresh home directory
+type Backup struct {
+	KeyBackup
+
+	Counters smstorage.Counters               `json:"counters"`
+	Chains   map[string]smstorage.ChainBackup `json:"chains,omitempty"`
+	// ChainKeys are the keys of the chains that have their own key
+	ChainKeys map[string]KeyBackup `json:"chain_keys,omitempty"`
+	// Ledger is the state of the built-in ledger, which holds the tokens the solo machine has sent out and received
+	Ledger *smstorage.LedgerBackup `json:"ledger,omitempty"`
+}
+
+type KeyBackup struct {
+	KeyAlgorithm string `json:"key_algorithm"`
+	Mnemonic     string `json:"mnemonic,omitempty"`
+	// PrivateKey is only set for keys that were not created from a mnemonic (same format as the file signer key file)
+	PrivateKey json.RawMessage `json:"private_key,omitempty"`
+}
+
+// WriteBackupFile writes the backup to a file only readable by the current user
+func WriteBackupFile(path string, backup *Backup) error {
+	bz, err := json.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bz, 0o600)
+}
+
+func ReadBackupFile(path string) (*Backup, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var backup Backup
+	if err := json.Unmarshal(bz, &backup); err != nil {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+// ExportBackup exports the local signer key (as a mnemonic if it was created from one), the state of the given chains and the built-in ledger
+func (sm *SoloMachine) ExportBackup(chainNames []string) (*Backup, error) {
+	if !sm.localKeyUnlocked {
+		return nil, fmt.Errorf("only the local signer key can be exported")
+	}
+
+	privKey, err := sm.storage.LocalPrivateKey(sm.localKeyPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic, _, err := sm.storage.LocalMnemonic(sm.localKeyPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	keyBackup, err := sm.keyBackup(privKey, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := sm.storage.LedgerBackup()
+	backup := &Backup{
+		KeyBackup: keyBackup,
+		Counters:  sm.storage.Counters(),
+		Chains:    make(map[string]smstorage.ChainBackup),
+		ChainKeys: make(map[string]KeyBackup),
+		Ledger:    &ledger,
+	}
+
+	for _, chainName := range chainNames {
+		chainStorage := sm.storage.GetChainStorage(chainName)
+		backup.Chains[chainName], err = chainStorage.Backup()
+		if err != nil {
+			return nil, err
+		}
+
+		if !chainStorage.HasPrivateKey() {
+			continue
+		}
+		chainPrivKey, err := chainStorage.PrivateKey(sm.localKeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		chainMnemonic, _, err := chainStorage.Mnemonic(sm.localKeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		backup.ChainKeys[chainName], err = sm.keyBackup(chainPrivKey, chainMnemonic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return backup, nil
+}
+
+// keyBackup exports the key as its mnemonic, or as the private key if it was not created from a mnemonic
+func (sm *SoloMachine) keyBackup(privKey cryptotypes.PrivKey, mnemonic string) (KeyBackup, error) {
+	keyBackup := KeyBackup{
+		KeyAlgorithm: privKey.Type(),
+		Mnemonic:     mnemonic,
+	}
+	if mnemonic != "" {
+		return keyBackup, nil
+	}
+
+	var err error
+	keyBackup.PrivateKey, err = sm.cdc.MarshalInterfaceJSON(privKey)
+	if err != nil {
+		return KeyBackup{}, err
+	}
+
+	return keyBackup, nil
+}
+
+// Recover rebuilds the solo machine identity from a backup in a fresh home directory
+// The local signer key is encrypted with the passphrase from the signer config, the same way as a newly created key.
+func Recover(logger *zap.Logger, cdc codec.Codec, homedir string, signerConfig signer.Config, backup *Backup) error {
+	sm := newSoloMachine(logger, cdc, nil, homedir)
+
+	localConfig, ok := localSignerConfig(signerConfig)
+	if !ok {
+		return fmt.Errorf("recovery is only supported for the local signer")
+	}
+	if sm.storage.HasLocalPrivateKey() {
+		return fmt.Errorf("a solo machine key already exists in %s, recover into a fresh home directory", homedir)
+	}
+
+	privKey, err := privKeyFromBackup(cdc, backup.KeyBackup)
+	if err != nil {
+		return err
+	}
+	if localConfig.KeyAlgorithm != "" && localConfig.KeyAlgorithm != privKey.Type() {
+		return fmt.Errorf("the recovered key has key algorithm %s, but the signer is configured with %s", privKey.Type(), localConfig.KeyAlgorithm)
+	}
+
+	for chainName, chainBackup := range backup.Chains {
+		if err := smstorage.ValidateChainName(chainName); err != nil {
+			return err
+		}
+		if err := sm.storage.GetChainStorage(chainName).Restore(chainBackup); err != nil {
+			return fmt.Errorf("chain %s: %w", chainName, err)
+		}
+		logger.Info("Restored chain", zap.String("chain", chainName), zap.String("counterparty-client-id", chainBackup.CounterpartyClientID))
+	}
+	sm.storage.RestoreCounters(backup.Counters)
+	if backup.Ledger != nil {
+		if err := sm.storage.RestoreLedger(*backup.Ledger); err != nil {
+			return err
+		}
+		logger.Info("Restored ledger", zap.Int("accounts", len(backup.Ledger.Balances)), zap.Int("denom-traces", len(backup.Ledger.DenomTraces)))
+	} else {
+		logger.Warn("The backup has no ledger, the local balances are not restored")
+	}
+
+	if localConfig.PassphraseFile != "" {
+		sm.passphraseFile = sm.homePath(localConfig.PassphraseFile)
+	}
+	passphrase, err := sm.keyPassphrase(true)
+	if err != nil {
+		return err
+	}
+
+	for chainName, chainKeyBackup := range backup.ChainKeys {
+		if err := smstorage.ValidateChainName(chainName); err != nil {
+			return err
+		}
+		chainPrivKey, err := privKeyFromBackup(cdc, chainKeyBackup)
+		if err != nil {
+			return fmt.Errorf("chain %s: %w", chainName, err)
+		}
+		if err := sm.storage.GetChainStorage(chainName).CreatePrivateKey(chainPrivKey, chainKeyBackup.Mnemonic, passphrase); err != nil {
+			return err
+		}
+		logger.Info("Recovered chain key", zap.String("chain", chainName), zap.String("public-key", chainPrivKey.PubKey().String()))
+	}
+
+	if err := sm.storage.CreateLocalPrivateKey(privKey, backup.Mnemonic, passphrase); err != nil {
+		return err
+	}
+	logger.Info("Recovered solo machine key", zap.String("key-algorithm", privKey.Type()), zap.String("public-key", privKey.PubKey().String()))
+
+	return nil
+}
+
+func privKeyFromBackup(cdc codec.Codec, backup KeyBackup) (cryptotypes.PrivKey, error) {
+	if backup.Mnemonic != "" {
+		keyAlgo := backup.KeyAlgorithm
+		if keyAlgo == "" {
+			keyAlgo = signer.DefaultKeyAlgo
+		}
+		return signer.KeyFromMnemonic(keyAlgo, backup.Mnemonic)
+	}
+
+	if len(backup.PrivateKey) == 0 {
+		return nil, fmt.Errorf("the backup has neither a mnemonic nor a private key")
+	}
+
+	var privKey cryptotypes.PrivKey
+	if err := cdc.UnmarshalInterfaceJSON(backup.PrivateKey, &privKey); err != nil {
+		return nil, err
+	}
+
+	return privKey, nil
+}
+
+// localSignerConfig finds the config of the local signer, either the signer itself or a multisig member
+func localSignerConfig(config signer.Config) (signer.Config, bool) {
+	switch config.Type {
+	case "", signer.TypeLocal:
+		return config, true
+	case signer.TypeMultisig:
+		for _, memberConfig := range config.Members {
+			if localConfig, ok := localSignerConfig(memberConfig); ok {
+				return localConfig, true
+			}
+		}
+	}
+
+	return signer.Config{}, false
+}
@@ -0,0 +1,319 @@
+package solomachine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTransferBatchSize is the default number of packets relayed in each tx of a transfer batch
+const DefaultTransferBatchSize = 50
+
+// ErrTransferBatchCompleted is returned when all the rows of a transfer batch were sent and acknowledged in earlier runs
+var ErrTransferBatchCompleted = errors.New("the transfer batch has already been completed")
+
+// TransferBatch is a list of transfers from the same sender, identified by the sender, the run id and the content of the file it was read from
+type TransferBatch struct {
+	ID   string
+	Rows []TransferBatchRow
+}
+
+type TransferBatchRow struct {
+	Receiver string      `json:"receiver"`
+	Denom    string      `json:"denom"`
+	Amount   json.Number `json:"amount"`
+}
+
+// TransferBatchResult counts how the rows of a transfer batch ended up, including rows sent in earlier (interrupted) runs
+// Unfinished rows have timed out or are still waiting for an acknowledgement, running the batch again picks them up.
+type TransferBatchResult struct {
+	Accepted   int
+	Rejected   int
+	Unfinished int
+}
+
+// ReadTransferBatchFile reads a JSON file (a list of objects with receiver, denom and amount) or a CSV file (receiver,denom,amount rows, with an optional header)
+// The run id tells a new run of the same file (e.g. a second airdrop) apart from a resumed one, it is empty for the first run.
+func ReadTransferBatchFile(path string, sender string, runID string) (*TransferBatch, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []TransferBatchRow
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(bz, &rows); err != nil {
+			return nil, err
+		}
+	} else {
+		reader := csv.NewReader(bytes.NewReader(bz))
+		reader.FieldsPerRecord = 3
+		reader.TrimLeadingSpace = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 && strings.EqualFold(records[0][0], "receiver") {
+			records = records[1:]
+		}
+		for _, record := range records {
+			rows = append(rows, TransferBatchRow{
+				Receiver: record[0],
+				Denom:    record[1],
+				Amount:   json.Number(record[2]),
+			})
+		}
+	}
+
+	for i, row := range rows {
+		if strings.TrimSpace(row.Receiver) == "" {
+			return nil, fmt.Errorf("row %d: receiver cannot be empty", i+1)
+		}
+		if err := sdk.ValidateDenom(row.Denom); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		if _, err := row.amount(); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	// The sender is part of the id, since the same file can be sent from different senders
+	// Without a run id, the id is the same as for batches stored before run ids were added, so they can still be resumed.
+	idPrefix := sender + "\n"
+	if runID != "" {
+		idPrefix += runID + "\n"
+	}
+	hash := sha256.Sum256(append([]byte(idPrefix), bz...))
+
+	return &TransferBatch{
+		ID:   hex.EncodeToString(hash[:16]),
+		Rows: rows,
+	}, nil
+}
+
+func (row TransferBatchRow) amount() (uint64, error) {
+	amount, err := strconv.ParseUint(row.Amount.String(), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", row.Amount, err)
+	}
+	if amount == 0 {
+		return 0, fmt.Errorf("amount cannot be zero")
+	}
+
+	return amount, nil
+}
+
+// TransferBatch sends the rows of the batch as ICS20 packets, with many MsgRecvPacket in each tx
+// The packet each row is sent with is recorded in storage before it is relayed, so running the same batch again resumes
+// where it stopped: rows that were sent are never sent again, but their packets are relayed again if the chain did not receive them.
+func (sm *SoloMachine) TransferBatch(chainName string, sender string, batch *TransferBatch, batchSize int, options TransferOptions) (TransferBatchResult, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if !chainStorage.ICS20ChannelExists() || !chainStorage.CounterpartyICS20ChannelExists() {
+		return TransferBatchResult{}, fmt.Errorf("no ICS20 channel for chain %s", chainName)
+	}
+	if batchSize <= 0 {
+		return TransferBatchResult{}, fmt.Errorf("batch size must be positive")
+	}
+
+	// The clients are only updated once for the whole batch
+	if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
+		return TransferBatchResult{}, err
+	}
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return TransferBatchResult{}, err
+	}
+
+	// Give some time for stuff to update
+	time.Sleep(5 * time.Second)
+
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return TransferBatchResult{}, err
+	}
+	proofHeight := lightClientState.LatestHeight
+	consensusState, err := chainStorage.GetLightConsensusState(proofHeight)
+	if err != nil {
+		return TransferBatchResult{}, err
+	}
+
+	var result TransferBatchResult
+	var resendPackets []channeltypes.Packet
+	var newRows []int
+	for row := range batch.Rows {
+		packetSequence, sent := chainStorage.TransferBatchPacketSequence(batch.ID, row)
+		if !sent {
+			newRows = append(newRows, row)
+			continue
+		}
+
+		packet, err := chainStorage.SentPacket(transfertypes.PortID, chainStorage.ICS20ChannelID(), packetSequence)
+		if err != nil {
+			return result, err
+		}
+		if _, ok := chainStorage.PacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence); !ok {
+			sm.tallyTransferBatchRow(chainName, &result, packet)
+			continue
+		}
+
+		// Sent in an interrupted run, the chain might have received it
+		receipt, _, err := sm.r.QueryProof(chainName, host.PacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), proofHeight)
+		if err != nil {
+			return result, err
+		}
+		switch {
+		case len(receipt) != 0:
+			if err := sm.acknowledgeReceivedPacket(chainName, packet, proofHeight); err != nil {
+				return result, err
+			}
+			sm.tallyTransferBatchRow(chainName, &result, packet)
+		case packetTimedOutOnChain(packet, proofHeight, consensusState.GetTimestamp()):
+			sm.logger.Warn("Packet of a transfer batch row timed out, use relay timeouts to reverse it", zap.String("chain", chainName), zap.Int("row", row+1), zap.Uint64("packet-sequence", packet.Sequence))
+			result.Unfinished++
+		default:
+			resendPackets = append(resendPackets, packet)
+		}
+	}
+
+	if len(batch.Rows) != 0 && len(newRows) == 0 && len(resendPackets) == 0 && result.Unfinished == 0 {
+		return result, fmt.Errorf("%w: all %d rows of batch %s were sent and acknowledged in earlier runs (%d accepted, %d rejected)",
+			ErrTransferBatchCompleted, len(batch.Rows), batch.ID, result.Accepted, result.Rejected)
+	}
+
+	sm.logger.Info("Transfer batch",
+		zap.String("chain", chainName),
+		zap.String("batch-id", batch.ID),
+		zap.Int("rows", len(batch.Rows)),
+		zap.Int("new", len(newRows)),
+		zap.Int("resend", len(resendPackets)),
+	)
+
+	for start := 0; start < len(resendPackets); start += batchSize {
+		packets := resendPackets[start:min(start+batchSize, len(resendPackets))]
+		if err := sm.relayTransferBatchPackets(chainName, &result, packets, proofHeight); err != nil {
+			return result, err
+		}
+	}
+
+	for start := 0; start < len(newRows); start += batchSize {
+		rows := newRows[start:min(start+batchSize, len(newRows))]
+		// The rows sent before a row the state machine rejected are still relayed
+		packets, sendErr := sm.sendTransferBatchRows(chainName, sender, batch, rows, proofHeight, options)
+		if len(packets) != 0 {
+			if err := sm.relayTransferBatchPackets(chainName, &result, packets, proofHeight); err != nil {
+				return result, err
+			}
+		}
+		if sendErr != nil {
+			return result, sendErr
+		}
+	}
+
+	return result, nil
+}
+
+// sendTransferBatchRows creates the packets for the rows, applies them with the state machine and stores them, before anything is relayed
+// If the state machine rejects a row, the rows before it are stored anyway (since they have been applied) and returned with the error.
+func (sm *SoloMachine) sendTransferBatchRows(chainName string, sender string, batch *TransferBatch, rows []int, latestHeight clienttypes.Height, options TransferOptions) ([]channeltypes.Packet, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	nextSequenceSend := chainStorage.NextSequenceSend(transfertypes.PortID, chainStorage.ICS20ChannelID())
+
+	packets := make([]channeltypes.Packet, len(rows))
+	for i, row := range rows {
+		transferRow := batch.Rows[row]
+		amount, err := transferRow.amount()
+		if err != nil {
+			return nil, err
+		}
+
+		packets[i], err = sm.newTransferPacket(chainName, latestHeight, nextSequenceSend+uint64(i), sender, transferRow.Receiver, transferRow.Denom, amount, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// With the built-in ledger the funds for all the rows are checked up front, so taking the tokens of a row cannot fail halfway
+	if sm.usesBuiltInLedger() {
+		var total sdk.Coins
+		for _, packet := range packets {
+			_, coin, err := transferPacketData(packet)
+			if err != nil {
+				return nil, err
+			}
+			total = total.Add(coin)
+		}
+		for _, coin := range total {
+			if balance := sm.storage.Balance(sender, coin.Denom); balance.LT(coin.Amount) {
+				return nil, fmt.Errorf("%w: %s has %s%s, the batch needs %s", smstorage.ErrInsufficientFunds, sender, balance, coin.Denom, coin)
+			}
+		}
+	}
+
+	var sendErr error
+	commitments := make([][]byte, 0, len(packets))
+	for i := range packets {
+		packet, err := sm.onSendPacket(chainName, packets[i])
+		if err != nil {
+			sendErr = fmt.Errorf("row %d: %w", rows[i]+1, err)
+			packets, rows = packets[:i], rows[:i]
+			break
+		}
+		packets[i] = packet
+		commitments = append(commitments, channeltypes.CommitPacket(sm.cdc, packet))
+	}
+
+	if len(packets) != 0 {
+		if err := chainStorage.SendTransferBatchPackets(batch.ID, rows, packets, commitments); err != nil {
+			return nil, sm.revertSendPackets(chainName, packets, err)
+		}
+	}
+
+	return packets, sendErr
+}
+
+// relayTransferBatchPackets relays the packets in a single tx and counts the acknowledgements
+func (sm *SoloMachine) relayTransferBatchPackets(chainName string, result *TransferBatchResult, packets []channeltypes.Packet, proofHeight clienttypes.Height) error {
+	if _, err := sm.relayPackets(chainName, packets, proofHeight); err != nil {
+		return err
+	}
+
+	for _, packet := range packets {
+		sm.tallyTransferBatchRow(chainName, result, packet)
+	}
+
+	return nil
+}
+
+// tallyTransferBatchRow counts a sent packet from its stored acknowledgement
+func (sm *SoloMachine) tallyTransferBatchRow(chainName string, result *TransferBatchResult, packet channeltypes.Packet) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	acknowledgement, ok := chainStorage.CounterpartyAcknowledgement(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+	if !ok {
+		// Timed out (and reversed) or not acknowledged yet
+		result.Unfinished++
+		return
+	}
+
+	var ack channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil || !ack.Success() {
+		result.Rejected++
+		return
+	}
+	result.Accepted++
+}
@@ -0,0 +1,30 @@
+package solomachine
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadTransferBatchFileRunID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "airdrop.csv")
+	require.NoError(t, os.WriteFile(path, []byte("receiver,denom,amount\ncosmos1receiver,stake,10\n"), 0o600))
+
+	first, err := ReadTransferBatchFile(path, "alice", "")
+	require.NoError(t, err)
+	require.Len(t, first.Rows, 1)
+
+	resumed, err := ReadTransferBatchFile(path, "alice", "")
+	require.NoError(t, err)
+	require.Equal(t, first.ID, resumed.ID, "the same file from the same sender resumes the batch")
+
+	otherSender, err := ReadTransferBatchFile(path, "bob", "")
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, otherSender.ID)
+
+	secondRun, err := ReadTransferBatchFile(path, "alice", "2")
+	require.NoError(t, err)
+	require.NotEqual(t, first.ID, secondRun.ID, "a new run id sends the same file again")
+	require.Equal(t, first.Rows, secondRun.Rows)
+}
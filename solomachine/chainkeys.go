@@ -0,0 +1,174 @@
+package solomachine
+
+import (
+	"bytes"
+	"fmt"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	"go.uber.org/zap"
+)
+
+// chainSigner returns the signer for the chain, which is its own key if it has one and the shared signer otherwise
+// During a rotation of the shared key, the chains that already have the new key installed are signed with the new key.
+func (sm *SoloMachine) chainSigner(chainName string) (signer.Signer, error) {
+	if chainSigner, ok := sm.chainSigners[chainName]; ok {
+		return chainSigner, nil
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if !chainStorage.HasPrivateKey() {
+		return sm.sharedSigner(chainName)
+	}
+
+	passphrase, err := sm.keyPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := chainStorage.PrivateKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	chainSigner := signer.NewLocalSigner(privKey)
+	sm.chainSigners[chainName] = chainSigner
+
+	return chainSigner, nil
+}
+
+// sharedSigner returns the pending shared key if a key rotation has installed it on the counterparty light client of the chain,
+// and the shared signer otherwise
+func (sm *SoloMachine) sharedSigner(chainName string) (signer.Signer, error) {
+	installedPubKey := sm.storage.GetChainStorage(chainName).InstalledPendingKey()
+	if _, ok := sm.signer.(*signer.LocalSigner); !ok || installedPubKey == nil {
+		return sm.signer, nil
+	}
+
+	passphrase, err := sm.keyPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	pendingPrivKey, ok, err := sm.storage.PendingLocalPrivateKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || !bytes.Equal(pendingPrivKey.PubKey().Bytes(), installedPubKey) {
+		// Installed by an earlier rotation, which has finished since
+		return sm.signer, nil
+	}
+
+	chainSigner := signer.NewLocalSigner(pendingPrivKey)
+	sm.chainSigners[chainName] = chainSigner
+
+	return chainSigner, nil
+}
+
+// ChainPublicKey returns the public key used for the chain (its own key or the shared key)
+func (sm *SoloMachine) ChainPublicKey(chainName string) (cryptotypes.PubKey, error) {
+	chainSigner, err := sm.chainSigner(chainName)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainSigner.PubKey(), nil
+}
+
+// HasChainKey returns true if the chain has its own key instead of the shared key
+func (sm *SoloMachine) HasChainKey(chainName string) bool {
+	return sm.storage.GetChainStorage(chainName).HasPrivateKey()
+}
+
+// CreateChainKey generates a separate key for the chain, which is used instead of the shared key
+// It must be created before the counterparty light client, a chain that already has a client is moved to its own key with RotateChainKey.
+func (sm *SoloMachine) CreateChainKey(chainName string, keyAlgo string) error {
+	if sm.CounterpartyLightClientExists(chainName) {
+		return fmt.Errorf("the counterparty light client for chain %s already exists, use a key rotation to give it its own key", chainName)
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if chainStorage.HasPrivateKey() {
+		return fmt.Errorf("chain %s already has its own key", chainName)
+	}
+
+	if keyAlgo == "" {
+		keyAlgo = sm.storage.LocalKeyAlgorithm()
+	}
+	privKey, mnemonic, err := signer.GenerateKey(keyAlgo)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := sm.keyPassphrase(!sm.storage.HasLocalPrivateKey())
+	if err != nil {
+		return err
+	}
+	if err := chainStorage.CreatePrivateKey(privKey, mnemonic, passphrase); err != nil {
+		return err
+	}
+	sm.logger.Info("Created new key for the chain", zap.String("chain", chainName), zap.String("key-algorithm", keyAlgo))
+	printMnemonic(mnemonic)
+
+	return nil
+}
+
+// RotateChainKey replaces the key of the chain with a new key of its own, also if the chain uses the shared key now
+// It works like RotateKey, but only for the one chain: the key is pending until the counterparty light client has it.
+func (sm *SoloMachine) RotateChainKey(chainName string, newPrivKey cryptotypes.PrivKey) error {
+	if !sm.CounterpartyLightClientExists(chainName) {
+		return fmt.Errorf("no counterparty light client for chain %s, create the key with init instead", chainName)
+	}
+
+	passphrase, err := sm.keyPassphrase(false)
+	if err != nil {
+		return err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	pendingPrivKey, ok, err := chainStorage.PendingPrivateKey(passphrase)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if newPrivKey != nil && !newPrivKey.Equals(pendingPrivKey) {
+			return fmt.Errorf("a different key rotation is already in progress for chain %s, finish it before rotating to another key", chainName)
+		}
+		sm.logger.Info("Continuing key rotation in progress", zap.String("chain", chainName))
+	} else {
+		var mnemonic string
+		if newPrivKey == nil {
+			// The new key uses the same algorithm as the current key of the chain (or the shared local key)
+			keyAlgo := sm.storage.LocalKeyAlgorithm()
+			if chainStorage.HasPrivateKey() {
+				currentPubKey, err := sm.ChainPublicKey(chainName)
+				if err != nil {
+					return err
+				}
+				keyAlgo = currentPubKey.Type()
+			}
+
+			newPrivKey, mnemonic, err = signer.GenerateKey(keyAlgo)
+			if err != nil {
+				return err
+			}
+		}
+		if err := chainStorage.SetPendingPrivateKey(newPrivKey, mnemonic, passphrase); err != nil {
+			return err
+		}
+		if mnemonic != "" {
+			printMnemonic(mnemonic)
+		}
+		pendingPrivKey = newPrivKey
+	}
+
+	if err := sm.installPubKey(chainName, pendingPrivKey.PubKey()); err != nil {
+		return err
+	}
+
+	promotedPrivKey, err := chainStorage.PromotePendingPrivateKey(passphrase)
+	if err != nil {
+		return err
+	}
+	sm.chainSigners[chainName] = signer.NewLocalSigner(promotedPrivKey)
+	sm.logger.Info("Key rotation finished, the chain now uses its new key", zap.String("chain", chainName))
+
+	return nil
+}
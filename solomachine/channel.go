@@ -1,11 +1,16 @@
 package solomachine
 
 import (
+	"bytes"
+	"fmt"
 	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
 	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
 	"go.uber.org/zap"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -17,99 +22,532 @@ func (sm *SoloMachine) ICS20ChannelExists(chainName string) bool {
 func (sm *SoloMachine) CreateICS20Channel(chainName string) error {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
-	counterpartyClientID := chainStorage.CounterpartyClientID()
-	connectionID := chainStorage.ConnectionID()
-	counterpartyConnectionID := chainStorage.CounterpartyConnectionID()
-	ics20ChannelID := chainStorage.ICS20ChannelID()
-	counterpartyICS20ChannelID := chainStorage.CounterpartyICS20Channel()
-
 	if !chainStorage.CounterpartyICS20ChannelExists() {
 		if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
 			return err
 		}
+	}
+
+	open, err := sm.initICS20Channel(chainName)
+	if err != nil {
+		return err
+	}
+
+	chainStorage = sm.storage.GetChainStorage(chainName) // Reload, the channel ids might have been set by initICS20Channel
+	return sm.openChannel(chainName, transfertypes.PortID, chainStorage.ICS20ChannelID(), open)
+}
+
+// CreateChannel opens a channel between the counterparty port on the chain and the port on the solo machine, started by the chain,
+// and returns the channel id on the solo machine. If a channel between the ports is still in the handshake (e.g. after an interruption),
+// that channel is opened instead of a new one, also when the chain has only initialized it.
+func (sm *SoloMachine) CreateChannel(chainName string, portID string, counterpartyPortID string, version string, order channeltypes.Order) (string, error) {
+	if err := host.PortIdentifierValidator(portID); err != nil {
+		return "", fmt.Errorf("invalid port %s: %w", portID, err)
+	}
+	if err := host.PortIdentifierValidator(counterpartyPortID); err != nil {
+		return "", fmt.Errorf("invalid counterparty port %s: %w", counterpartyPortID, err)
+	}
+	if order != channeltypes.ORDERED && order != channeltypes.UNORDERED {
+		return "", fmt.Errorf("invalid channel ordering: %s", order)
+	}
+	if !sm.router.HasRoute(portID) {
+		return "", fmt.Errorf("no application is bound to port %s", portID)
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if chainStorage.ConnectionID() == "" || chainStorage.CounterpartyConnectionID() == "" {
+		return "", fmt.Errorf("no connection for chain %s", chainName)
+	}
+
+	channels, err := chainStorage.Channels()
+	if err != nil {
+		return "", err
+	}
+	var channel channeltypes.IdentifiedChannel
+	for _, c := range channels {
+		if c.PortId == portID && c.Counterparty.PortId == counterpartyPortID && c.State == channeltypes.TRYOPEN {
+			channel = c
+			sm.logger.Info("Resuming the handshake of channel", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channel.ChannelId))
+			break
+		}
+	}
+
+	if channel.ChannelId == "" {
+		if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
+			return "", err
+		}
+
+		var counterpartyChannel *channeltypes.Channel
+		counterpartyChannelID, ok := chainStorage.ChannelInit(portID, counterpartyPortID)
+		if ok {
+			counterpartyChannel, err = sm.r.QueryChannel(chainName, counterpartyPortID, counterpartyChannelID)
+			if err != nil {
+				return "", err
+			}
+			if counterpartyChannel.State == channeltypes.INIT && counterpartyChannel.Ordering == order {
+				sm.logger.Info("Resuming the handshake of the channel initialized on the chain", zap.String("chain", chainName), zap.String("port-id", counterpartyPortID), zap.String("channel-id", counterpartyChannelID))
+			} else {
+				counterpartyChannelID = ""
+			}
+		}
+
+		if counterpartyChannelID == "" {
+			counterpartyChannelID, err = sm.r.InitChannel(chainName, chainStorage.CounterpartyConnectionID(), counterpartyPortID, version, order, portID)
+			if err != nil {
+				return "", err
+			}
+			// Stored right away, so the channel is not left behind on the chain if the rest of the handshake fails
+			chainStorage.SetChannelInit(portID, counterpartyPortID, counterpartyChannelID)
+			sm.logger.Info("Channel initialized on the chain", zap.String("chain", chainName), zap.String("port-id", counterpartyPortID), zap.String("channel-id", counterpartyChannelID))
+
+			counterpartyChannel, err = sm.r.QueryChannel(chainName, counterpartyPortID, counterpartyChannelID)
+			if err != nil {
+				return "", err
+			}
+		}
+		if err := sm.UpdateLightClient(chainName); err != nil {
+			return "", err
+		}
+
+		channel.PortId = portID
+		channel.ChannelId = chainStorage.CreateChannel(portID)
+		if err := sm.chanOpenTry(chainName, portID, channel.ChannelId, counterpartyPortID, counterpartyChannelID, counterpartyChannel); err != nil {
+			return "", err
+		}
+		chainStorage.DeleteChannelInit(portID, counterpartyPortID)
+		channel.Counterparty = channeltypes.NewCounterparty(counterpartyPortID, counterpartyChannelID)
+	}
+
+	counterpartyChannel, err := sm.r.QueryChannel(chainName, counterpartyPortID, channel.Counterparty.ChannelId)
+	if err != nil {
+		return "", err
+	}
+	if err := sm.openChannel(chainName, portID, channel.ChannelId, counterpartyChannel.State == channeltypes.OPEN); err != nil {
+		return "", err
+	}
+
+	return channel.ChannelId, nil
+}
+
+// Channels returns the solo machine ends of all the channels to the chain, in any state
+func (sm *SoloMachine) Channels(chainName string) ([]channeltypes.IdentifiedChannel, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	return chainStorage.Channels()
+}
+
+// openChannels returns the channels that are open on the solo machine, which are the channels packets are relayed on
+func (sm *SoloMachine) openChannels(chainName string) ([]channeltypes.IdentifiedChannel, error) {
+	return sm.channelsInState(chainName, channeltypes.OPEN)
+}
+
+// channelsInState returns the channels of the solo machine in one of the states, and an error if there are none
+func (sm *SoloMachine) channelsInState(chainName string, states ...channeltypes.State) ([]channeltypes.IdentifiedChannel, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channels, err := chainStorage.Channels()
+	if err != nil {
+		return nil, err
+	}
+
+	var channelsInState []channeltypes.IdentifiedChannel
+	for _, channel := range channels {
+		if slices.Contains(states, channel.State) {
+			channelsInState = append(channelsInState, channel)
+		}
+	}
+
+	if chainStorage.ICS20ChannelExists() {
+		if _, ok := chainStorage.Channel(transfertypes.PortID, chainStorage.ICS20ChannelID()); !ok {
+			sm.logger.Warn("The ICS20 channel was created before the channel ends were stored, run init again to store it", zap.String("chain", chainName), zap.String("channel-id", chainStorage.ICS20ChannelID()))
+		}
+	}
+	if len(channelsInState) == 0 {
+		stateNames := make([]string, len(states))
+		for i, state := range states {
+			stateNames[i] = state.String()
+		}
+		return nil, fmt.Errorf("no channels in state %s for chain %s", strings.Join(stateNames, " or "), chainName)
+	}
+
+	return channelsInState, nil
+}
 
+// openChannel finishes the handshake of a channel the solo machine has stored in TRYOPEN: the channel open ack is relayed
+// to the chain, unless the chain has opened the channel already, and the solo machine end of the channel is opened
+func (sm *SoloMachine) openChannel(chainName string, portID string, channelID string, counterpartyOpen bool) error {
+	if counterpartyOpen {
+		// All good, channel is already open on the chain (but the solo machine might not have confirmed it yet)
+		return sm.chanOpenConfirm(chainName, portID, channelID)
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return err
+	}
+
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return err
+	}
+
+	tryProof, err := sm.generateChanOpenTryProof(chainName, portID, channelID, sequence)
+	if err != nil {
+		return err
+	}
+
+	return sm.channelOpenAck(chainName, portID, channelID, tryProof, lightClientState)
+}
+
+// initICS20Channel runs the handshake steps that do not need a signature from the solo machine (init on the chain and the solo machine side of the channel)
+// It returns true if the channel is already open on the chain.
+func (sm *SoloMachine) initICS20Channel(chainName string) (bool, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	counterpartyConnectionID := chainStorage.CounterpartyConnectionID()
+	ics20ChannelID := chainStorage.ICS20ChannelID()
+	counterpartyICS20ChannelID := chainStorage.CounterpartyICS20Channel()
+
+	if !chainStorage.CounterpartyICS20ChannelExists() {
 		var err error
 		counterpartyICS20ChannelID, err = sm.r.InitChannel(
 			chainName,
 			counterpartyConnectionID,
 			transfertypes.PortID,
 			transfertypes.Version,
+			channeltypes.UNORDERED,
 			transfertypes.PortID,
 		)
 		if err != nil {
-			return err
+			return false, err
 		}
 		chainStorage.SetCounterpartyICS20ChannelID(counterpartyICS20ChannelID)
 		sm.logger.Info("ICS20 channel initialized on the chain", zap.String("chain", chainName), zap.String("channel-id", counterpartyICS20ChannelID))
 	}
 
+	counterpartyChannel, err := sm.r.QueryChannel(chainName, transfertypes.PortID, counterpartyICS20ChannelID)
+	if err != nil {
+		return false, err
+	}
+
 	if !chainStorage.ICS20ChannelExists() {
 		if err := sm.UpdateLightClient(chainName); err != nil {
-			return err
+			return false, err
 		}
 
-		// Similar to OPEN_TRY, sort-of, except we don't keep that as a state
+		// Similar to OPEN_TRY, sort-of, the channel end is stored with the TRYOPEN state below
 		ics20ChannelID = chainStorage.CreateICS20Channel()
 		sm.logger.Info("Created ICS20 channel on solo machine", zap.String("for chain", chainName), zap.String("channel-id", ics20ChannelID))
 	}
 
-	counterpartyChannel, err := sm.r.QueryChannel(chainName, transfertypes.PortID, counterpartyICS20ChannelID)
+	// Channels created before the channel ends were stored get theirs here as well
+	if _, ok := chainStorage.Channel(transfertypes.PortID, ics20ChannelID); !ok {
+		if err := sm.chanOpenTry(chainName, transfertypes.PortID, ics20ChannelID, transfertypes.PortID, counterpartyICS20ChannelID, counterpartyChannel); err != nil {
+			return false, err
+		}
+	}
+
+	return counterpartyChannel.State == channeltypes.OPEN, nil
+}
+
+// chanOpenInit lets the application bound to the port propose a channel to the chain, and stores the solo machine end of it
+func (sm *SoloMachine) chanOpenInit(chainName string, portID string, counterpartyPortID string, order channeltypes.Order, version string) (string, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	module, err := sm.route(portID)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if counterpartyChannel.State == channeltypes.OPEN {
-		return nil // All good, channel is already open
+
+	channelID := chainStorage.CreateChannel(portID)
+	counterparty := channeltypes.NewCounterparty(counterpartyPortID, "")
+	connectionHops := []string{chainStorage.ConnectionID()}
+	version, err = module.OnChanOpenInit(chainName, order, connectionHops, portID, channelID, counterparty, version)
+	if err != nil {
+		return "", fmt.Errorf("the application on port %s rejected channel %s: %w", portID, channelID, err)
 	}
 
-	counterpartyClientState, err := sm.r.GetClientState(chainName, counterpartyClientID)
+	channel := channeltypes.NewChannel(channeltypes.INIT, order, counterparty, connectionHops, version)
+	chainStorage.SetChannel(portID, channelID, channel)
+	sm.logger.Info("Channel initialized on solo machine", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channelID))
+
+	return channelID, nil
+}
+
+// channelOpenTry relays the channel open try to the chain with the proof of the initialized solo machine end of the channel,
+// and stores the channel id the chain picked as the counterparty of the channel
+func (sm *SoloMachine) channelOpenTry(chainName string, portID string, channelID string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
 	if err != nil {
 		return err
 	}
-	sequence := counterpartyClientState.Sequence
-
 	lightClientState, err := chainStorage.LightClientState()
 	if err != nil {
 		return err
 	}
 
-	tryProof, err := sm.generateChanOpenTryProof(
+	signBytes, err := sm.channelSignBytes(chainName, portID, channelID, channeltypes.INIT, sequence)
+	if err != nil {
+		return err
+	}
+	initProof, err := sm.GenerateProof(chainName, signBytes)
+	if err != nil {
+		return err
+	}
+
+	counterpartyChannelID, err := sm.r.ChannelOpenTry(
 		chainName,
-		sequence,
-		connectionID,
-		transfertypes.PortID,
-		ics20ChannelID,
-		transfertypes.Version,
-		transfertypes.PortID,
-		counterpartyICS20ChannelID,
+		channel.Counterparty.PortId,
+		channel.Ordering,
+		chainStorage.CounterpartyConnectionID(),
+		portID,
+		channelID,
+		channel.Version,
+		initProof,
+		lightClientState.LatestHeight,
 	)
+	if err != nil {
+		return err
+	}
+
+	channel.Counterparty.ChannelId = counterpartyChannelID
+	chainStorage.SetChannel(portID, channelID, channel)
+
+	return nil
+}
+
+// chanOpenAck verifies the channel end the chain stored in the channel open try against the light client,
+// lets the application bound to the port accept the version of the chain, and opens the solo machine end of the channel
+func (sm *SoloMachine) chanOpenAck(chainName string, portID string, channelID string) error {
+	// Give some time for the channel open try to be committed on the chain
+	time.Sleep(5 * time.Second)
+
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return err
+	}
+	proofHeight := lightClientState.LatestHeight
+
+	counterpartyChannelBz, counterpartyChannelProof, err := sm.r.QueryProof(chainName, host.ChannelKey(channel.Counterparty.PortId, channel.Counterparty.ChannelId), proofHeight)
+	if err != nil {
+		return err
+	}
+	var counterpartyChannel channeltypes.Channel
+	if err := sm.cdc.Unmarshal(counterpartyChannelBz, &counterpartyChannel); err != nil {
+		return err
+	}
+
+	expectedCounterparty := channeltypes.NewCounterparty(portID, channelID)
+	expectedChannel := channeltypes.NewChannel(channeltypes.TRYOPEN, channel.Ordering, expectedCounterparty, []string{chainStorage.CounterpartyConnectionID()}, counterpartyChannel.Version)
+	if !bytes.Equal(sm.cdc.MustMarshal(&expectedChannel), counterpartyChannelBz) {
+		return fmt.Errorf("channel %s on the chain is not the channel open try of channel %s: %s", channel.Counterparty.ChannelId, channelID, counterpartyChannel.String())
+	}
+
+	counterpartyChannelPath, err := ibcMerklePath(host.ChannelPath(channel.Counterparty.PortId, channel.Counterparty.ChannelId))
+	if err != nil {
+		return err
+	}
+	if err := chainStorage.VerifyMembership(sm.verificationContext(), proofHeight, counterpartyChannelProof, counterpartyChannelPath, counterpartyChannelBz); err != nil {
+		return fmt.Errorf("failed to verify channel %s on the chain: %w", channel.Counterparty.ChannelId, err)
+	}
+
+	module, err := sm.route(portID)
+	if err != nil {
+		return err
+	}
+	if err := module.OnChanOpenAck(chainName, portID, channelID, channel.Counterparty.ChannelId, counterpartyChannel.Version); err != nil {
+		return fmt.Errorf("the application on port %s rejected the version of channel %s: %w", portID, channelID, err)
+	}
+
+	channel.State = channeltypes.OPEN
+	channel.Version = counterpartyChannel.Version
+	chainStorage.SetChannel(portID, channelID, channel)
+	sm.logger.Info("Channel open", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channelID))
+
+	return nil
+}
+
+// channelOpenConfirm relays the channel open confirm to the chain with the proof of the open solo machine end of the channel
+func (sm *SoloMachine) channelOpenConfirm(chainName string, portID string, channelID string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return err
+	}
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return err
+	}
+
+	signBytes, err := sm.channelSignBytes(chainName, portID, channelID, channeltypes.OPEN, sequence)
+	if err != nil {
+		return err
+	}
+	ackProof, err := sm.GenerateProof(chainName, signBytes)
+	if err != nil {
+		return err
+	}
+
+	return sm.r.ChannelOpenConfirm(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId, ackProof, lightClientState.LatestHeight)
+}
+
+// channelCloseConfirm relays the channel close confirm to the chain with the proof of the closed solo machine end of the channel,
+// which closes the end on the chain too
+func (sm *SoloMachine) channelCloseConfirm(chainName string, portID string, channelID string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return err
+	}
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return err
+	}
+
+	signBytes, err := sm.channelSignBytes(chainName, portID, channelID, channeltypes.CLOSED, sequence)
+	if err != nil {
+		return err
+	}
+	initProof, err := sm.GenerateProof(chainName, signBytes)
+	if err != nil {
+		return err
+	}
+
+	if err := sm.r.ChannelCloseConfirm(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId, initProof, lightClientState.LatestHeight); err != nil {
+		return err
+	}
+	sm.logger.Info("Channel closed on the chain", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channelID))
+
+	return nil
+}
+
+// chanOpenTry lets the application bound to the port agree to the channel the chain initialized, and stores the solo machine end of it
+func (sm *SoloMachine) chanOpenTry(chainName string, portID string, channelID string, counterpartyPortID string, counterpartyChannelID string, counterpartyChannel *channeltypes.Channel) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	module, err := sm.route(portID)
+	if err != nil {
+		return err
+	}
+
+	counterparty := channeltypes.NewCounterparty(counterpartyPortID, counterpartyChannelID)
+	connectionHops := []string{chainStorage.ConnectionID()}
+	version, err := module.OnChanOpenTry(chainName, counterpartyChannel.Ordering, connectionHops, portID, channelID, counterparty, counterpartyChannel.Version)
+	if err != nil {
+		return fmt.Errorf("the application on port %s rejected channel %s: %w", portID, channelID, err)
+	}
+
+	channel := channeltypes.NewChannel(channeltypes.TRYOPEN, counterpartyChannel.Ordering, counterparty, connectionHops, version)
+	chainStorage.SetChannel(portID, channelID, channel)
+
+	return nil
+}
+
+// channelOpenAck relays the channel open ack to the chain with the proof of the solo machine end of the channel, and confirms the channel
+func (sm *SoloMachine) channelOpenAck(chainName string, portID string, channelID string, tryProof []byte, lightClientState *tmclient.ClientState) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
 
 	if err := sm.r.ChannelOpenAck(
 		chainName,
-		counterpartyICS20ChannelID,
-		ics20ChannelID,
+		channel.Counterparty.PortId,
+		channel.Counterparty.ChannelId,
+		channelID,
+		channel.Version,
 		tryProof,
-		lightClientState.LatestHeight); err != nil {
+		lightClientState.LatestHeight,
+	); err != nil {
 		return err
 	}
 
+	return sm.chanOpenConfirm(chainName, portID, channelID)
+}
+
+// chanOpenConfirm opens the solo machine end of the channel once it is open on the chain, and lets the application of the port know
+func (sm *SoloMachine) chanOpenConfirm(chainName string, portID string, channelID string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+	if channel.State == channeltypes.OPEN {
+		return nil
+	}
+
+	module, err := sm.route(portID)
+	if err != nil {
+		return err
+	}
+	if err := module.OnChanOpenConfirm(chainName, portID, channelID); err != nil {
+		return err
+	}
+
+	channel.State = channeltypes.OPEN
+	chainStorage.SetChannel(portID, channelID, channel)
+	sm.logger.Info("Channel open", zap.String("chain", chainName), zap.String("port-id", portID), zap.String("channel-id", channelID))
+
 	return nil
 }
 
 // generateChanOpenTryProof generates the proofTry required for the channel open ack handshake step.
-func (sm *SoloMachine) generateChanOpenTryProof(
-	chainName string,
-	sequence uint64,
-	connectionID string,
-	portID string,
-	channelID string,
-	version string,
-	counterpartyPortID string,
-	counterpartyChannelID string,
-) ([]byte, error) {
+func (sm *SoloMachine) generateChanOpenTryProof(chainName string, portID string, channelID string, sequence uint64) ([]byte, error) {
+	signBytes, err := sm.chanOpenTrySignBytes(chainName, portID, channelID, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm.GenerateProof(chainName, signBytes)
+}
+
+func (sm *SoloMachine) chanOpenTrySignBytes(chainName string, portID string, channelID string, sequence uint64) (*solomachineclient.SignBytes, error) {
+	// The proof is of the channel end in the TRYOPEN state, also when it is signed again after an interrupted handshake
+	return sm.channelSignBytes(chainName, portID, channelID, channeltypes.TRYOPEN, sequence)
+}
+
+// channelSignBytes creates the sign bytes of the stored solo machine end of a channel, as it is (or was) in the given state
+func (sm *SoloMachine) channelSignBytes(chainName string, portID string, channelID string, state channeltypes.State, sequence uint64) (*solomachineclient.SignBytes, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
-	counterparty := channeltypes.NewCounterparty(counterpartyPortID, counterpartyChannelID)
-	channel := channeltypes.NewChannel(channeltypes.TRYOPEN, channeltypes.UNORDERED, counterparty, []string{connectionID}, version)
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return nil, fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+	channel.State = state
 
 	data, err := sm.cdc.Marshal(&channel)
 	if err != nil {
@@ -117,13 +555,11 @@ func (sm *SoloMachine) generateChanOpenTryProof(
 	}
 
 	path := host.ChannelKey(portID, channelID)
-	signBytes := &solomachineclient.SignBytes{
+	return &solomachineclient.SignBytes{
 		Sequence:    sequence,
 		Timestamp:   uint64(time.Now().UnixMilli()),
 		Diversifier: chainStorage.Diversifier(),
 		Path:        path,
 		Data:        data,
-	}
-
-	return sm.GenerateProof(signBytes)
+	}, nil
 }
@@ -18,7 +18,9 @@ func (sm *SoloMachine) ConnectionExists(chainName string) bool {
 	return chainStorage.ConnectionExists()
 }
 
-func (sm *SoloMachine) CreateConnection(chainName string) error {
+// initConnection runs the handshake steps that do not need a signature from the solo machine (init on the chain and the solo machine side of the connection)
+// It returns true if the connection is already open on the chain.
+func (sm *SoloMachine) initConnection(chainName string) (bool, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
 	clientID := chainStorage.ClientID()
@@ -27,14 +29,10 @@ func (sm *SoloMachine) CreateConnection(chainName string) error {
 	counterpartyConnectionID := chainStorage.CounterpartyConnectionID()
 
 	if !chainStorage.CounterpartyConnectionExists() {
-		if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
-			return err
-		}
-
 		var err error
 		counterpartyConnectionID, err = sm.r.InitConnection(chainName, counterpartyClientID, clientID)
 		if err != nil {
-			return err
+			return false, err
 		}
 		chainStorage.SetCounterpartyConnectionID(counterpartyConnectionID)
 		sm.logger.Info("Connection initialized on the chain", zap.String("chain", chainName), zap.String("connection-id", counterpartyConnectionID))
@@ -42,7 +40,7 @@ func (sm *SoloMachine) CreateConnection(chainName string) error {
 
 	if !chainStorage.ConnectionExists() {
 		if err := sm.UpdateLightClient(chainName); err != nil {
-			return err
+			return false, err
 		}
 
 		// Similar to OPEN_TRY, sort-of, except we don't keep that as a state
@@ -53,15 +51,40 @@ func (sm *SoloMachine) CreateConnection(chainName string) error {
 
 	counterpartyConnectionEnd, err := sm.r.QueryConnection(chainName, counterpartyConnectionID)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if counterpartyConnectionEnd.State == connectiontypes.OPEN {
-		return nil // All good, connection is already open
+		return true, nil
 	}
 	if counterpartyConnectionEnd.State != connectiontypes.INIT {
-		return fmt.Errorf("unexpected connection state: wanted %s, got %s", connectiontypes.INIT, counterpartyConnectionEnd.State)
+		return false, fmt.Errorf("unexpected connection state: wanted %s, got %s", connectiontypes.INIT, counterpartyConnectionEnd.State)
+	}
+
+	return false, nil
+}
+
+func (sm *SoloMachine) CreateConnection(chainName string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	if !chainStorage.CounterpartyConnectionExists() {
+		if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
+			return err
+		}
+	}
+
+	open, err := sm.initConnection(chainName)
+	if err != nil {
+		return err
+	}
+	if open {
+		return nil // All good, connection is already open
 	}
 
+	chainStorage = sm.storage.GetChainStorage(chainName) // Reload, the connection ids might have been set by initConnection
+	counterpartyClientID := chainStorage.CounterpartyClientID()
+	connectionID := chainStorage.ConnectionID()
+	counterpartyConnectionID := chainStorage.CounterpartyConnectionID()
+
 	counterpartyClientState, err := sm.r.GetClientState(chainName, counterpartyClientID)
 	if err != nil {
 		return err
@@ -116,6 +139,15 @@ func (sm *SoloMachine) CreateConnection(chainName string) error {
 // GenerateConnOpenTryProof generates the proofTry required for the connection open ack handshake step.
 // The clientID, connectionID provided represent the clientID and connectionID created on the counterparty chain, that is the tendermint chain.
 func (sm *SoloMachine) GenerateConnOpenTryProof(chainName string, sequence uint64) ([]byte, error) {
+	signBytes, err := sm.connOpenTrySignBytes(chainName, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm.GenerateProof(chainName, signBytes)
+}
+
+func (sm *SoloMachine) connOpenTrySignBytes(chainName string, sequence uint64) (*solomachineclient.SignBytes, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
 	merklePrefix := commitmenttypes.NewMerklePrefix([]byte(exported.StoreKey))
@@ -148,10 +180,19 @@ func (sm *SoloMachine) GenerateConnOpenTryProof(chainName string, sequence uint6
 
 	sm.logger.Debug("generated sign bytes", zap.Uint64("sequence", sequence), zap.Uint64("timestamp", signBytes.Timestamp), zap.String("diversifier", signBytes.Diversifier), zap.String("path", string(signBytes.Path)), zap.String("data", string(signBytes.Data)))
 
-	return sm.GenerateProof(signBytes)
+	return signBytes, nil
 }
 
 func (sm *SoloMachine) GenerateClientStateProof(chainName string, sequence uint64, clientState exported.ClientState) ([]byte, error) {
+	signBytes, err := sm.clientStateSignBytes(chainName, sequence, clientState)
+	if err != nil {
+		return nil, err
+	}
+
+	return sm.GenerateProof(chainName, signBytes)
+}
+
+func (sm *SoloMachine) clientStateSignBytes(chainName string, sequence uint64, clientState exported.ClientState) (*solomachineclient.SignBytes, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
 	data, err := ibcclienttypes.MarshalClientState(sm.cdc, clientState)
@@ -160,18 +201,25 @@ func (sm *SoloMachine) GenerateClientStateProof(chainName string, sequence uint6
 	}
 
 	path := host.FullClientStateKey(chainStorage.ClientID())
-	signBytes := &solomachineclient.SignBytes{
+	return &solomachineclient.SignBytes{
 		Sequence:    sequence,
 		Timestamp:   uint64(time.Now().UnixMilli()),
 		Diversifier: chainStorage.Diversifier(),
 		Path:        path,
 		Data:        data,
+	}, nil
+}
+
+func (sm *SoloMachine) GenerateConsensusStateProof(chainName string, sequence uint64, clientState *tmclient.ClientState) ([]byte, error) {
+	signBytes, err := sm.consensusStateSignBytes(chainName, sequence, clientState)
+	if err != nil {
+		return nil, err
 	}
 
-	return sm.GenerateProof(signBytes)
+	return sm.GenerateProof(chainName, signBytes)
 }
 
-func (sm *SoloMachine) GenerateConsensusStateProof(chainName string, sequence uint64, clientState *tmclient.ClientState) ([]byte, error) {
+func (sm *SoloMachine) consensusStateSignBytes(chainName string, sequence uint64, clientState *tmclient.ClientState) (*solomachineclient.SignBytes, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
 	height := clientState.LatestHeight
@@ -186,13 +234,11 @@ func (sm *SoloMachine) GenerateConsensusStateProof(chainName string, sequence ui
 	}
 
 	path := host.FullConsensusStateKey(chainStorage.ClientID(), height)
-	signBytes := &solomachineclient.SignBytes{
+	return &solomachineclient.SignBytes{
 		Sequence:    sequence,
 		Timestamp:   uint64(time.Now().UnixMilli()),
 		Diversifier: chainStorage.Diversifier(),
 		Path:        path,
 		Data:        data,
-	}
-
-	return sm.GenerateProof(signBytes)
+	}, nil
 }
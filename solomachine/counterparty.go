@@ -2,6 +2,7 @@ package solomachine
 
 import (
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
 	"go.uber.org/zap"
 	"time"
@@ -10,7 +11,11 @@ import (
 func (sm *SoloMachine) CreateCounterpartyLightClient(chainName string) error {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
-	publicKey, err := codectypes.NewAnyWithValue(sm.storage.GetPublicKey())
+	pubKey, err := sm.ChainPublicKey(chainName)
+	if err != nil {
+		return err
+	}
+	publicKey, err := codectypes.NewAnyWithValue(pubKey)
 	if err != nil {
 		return err
 	}
@@ -42,7 +47,11 @@ func (sm *SoloMachine) UpdateCounterpartyLightClient(chainName string) error {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 	clientID := chainStorage.CounterpartyClientID()
 
-	soloMachineHeader, err := sm.createSoloMachineHeader(chainName)
+	pubKey, err := sm.ChainPublicKey(chainName)
+	if err != nil {
+		return err
+	}
+	soloMachineHeader, err := sm.createSoloMachineHeader(chainName, pubKey, chainStorage.Diversifier())
 	if err != nil {
 		return err
 	}
@@ -55,55 +64,68 @@ func (sm *SoloMachine) UpdateCounterpartyLightClient(chainName string) error {
 	return nil
 }
 
-func (sm *SoloMachine) createSoloMachineHeader(chainName string) (*solomachineclient.Header, error) {
+// createSoloMachineHeader creates a header, signed by the current key of the chain, that updates the counterparty light client and installs newPubKey and newDiversifier
+func (sm *SoloMachine) createSoloMachineHeader(chainName string, newPubKey cryptotypes.PubKey, newDiversifier string) (*solomachineclient.Header, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 	clientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
 	if err != nil {
 		return nil, err
 	}
 
-	diversifier := chainStorage.Diversifier()
-	publicKey, err := codectypes.NewAnyWithValue(sm.storage.GetPublicKey())
+	signBytes, header, err := sm.soloMachineHeaderSignBytes(chainName, clientState.Sequence, newPubKey, newDiversifier)
+	if err != nil {
+		return nil, err
+	}
+
+	bz, err := sm.cdc.Marshal(signBytes)
 	if err != nil {
 		return nil, err
 	}
 
+	sig, err := sm.GenerateSignature(chainName, bz)
+	if err != nil {
+		return nil, err
+	}
+	header.Signature = sig
+
+	return header, nil
+}
+
+// soloMachineHeaderSignBytes creates the sign bytes for a header update at the given sequence, together with the header (without the signature)
+// The sign bytes use the current diversifier, while the header installs the new public key and diversifier.
+func (sm *SoloMachine) soloMachineHeaderSignBytes(chainName string, sequence uint64, newPubKey cryptotypes.PubKey, newDiversifier string) (*solomachineclient.SignBytes, *solomachineclient.Header, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	publicKey, err := codectypes.NewAnyWithValue(newPubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	data := &solomachineclient.HeaderData{
 		NewPubKey:      publicKey,
-		NewDiversifier: diversifier,
+		NewDiversifier: newDiversifier,
 	}
 
 	dataBz, err := sm.cdc.Marshal(data)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	timestamp := uint64(time.Now().UnixMilli())
 
 	signBytes := &solomachineclient.SignBytes{
-		Sequence:    clientState.Sequence,
+		Sequence:    sequence,
 		Timestamp:   timestamp,
-		Diversifier: diversifier,
+		Diversifier: chainStorage.Diversifier(),
 		Path:        []byte(solomachineclient.SentinelHeaderPath),
 		Data:        dataBz,
 	}
 
-	bz, err := sm.cdc.Marshal(signBytes)
-	if err != nil {
-		return nil, err
-	}
-
-	sig, err := sm.GenerateSignature(bz)
-	if err != nil {
-		return nil, err
-	}
-
 	header := &solomachineclient.Header{
 		Timestamp:      timestamp,
-		Signature:      sig,
 		NewPublicKey:   publicKey,
-		NewDiversifier: diversifier,
+		NewDiversifier: newDiversifier,
 	}
 
-	return header, nil
+	return signBytes, header, nil
 }
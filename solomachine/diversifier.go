@@ -0,0 +1,56 @@
+package solomachine
+
+import (
+	"fmt"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"go.uber.org/zap"
+)
+
+// RotateDiversifier installs a new diversifier on the counterparty solo machine client for the chain and returns it
+// Signatures produced with the old diversifier, but never submitted, are no longer valid after the rotation.
+// The new diversifier is stored as pending until the chain has confirmed it, so an interrupted rotation can be continued by running it again.
+// If newDiversifier is empty, a random diversifier is generated (it is only used if there is no pending rotation).
+func (sm *SoloMachine) RotateDiversifier(chainName string, newDiversifier string) (string, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	pendingDiversifier, ok := chainStorage.PendingDiversifier()
+	if ok {
+		if newDiversifier != "" && newDiversifier != pendingDiversifier {
+			return "", fmt.Errorf("a rotation to diversifier %s is already in progress, finish it before rotating to another diversifier", pendingDiversifier)
+		}
+		sm.logger.Info("Continuing diversifier rotation in progress", zap.String("chain", chainName))
+	} else {
+		if newDiversifier == "" {
+			newDiversifier = smstorage.NewDiversifier()
+		}
+		if newDiversifier == chainStorage.Diversifier() {
+			return "", fmt.Errorf("new diversifier must be different from the current diversifier")
+		}
+		chainStorage.SetPendingDiversifier(newDiversifier)
+		pendingDiversifier = newDiversifier
+	}
+
+	clientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return "", err
+	}
+
+	if clientState.ConsensusState.Diversifier != pendingDiversifier {
+		pubKey, err := sm.ChainPublicKey(chainName)
+		if err != nil {
+			return "", err
+		}
+		header, err := sm.createSoloMachineHeader(chainName, pubKey, pendingDiversifier)
+		if err != nil {
+			return "", err
+		}
+		if err := sm.r.UpdateClient(chainName, chainStorage.CounterpartyClientID(), header); err != nil {
+			return "", fmt.Errorf("failed to install the new diversifier (run the rotation again to continue): %w", err)
+		}
+	}
+
+	chainStorage.PromotePendingDiversifier()
+	sm.logger.Info("Rotated diversifier", zap.String("chain", chainName), zap.String("diversifier", pendingDiversifier))
+
+	return pendingDiversifier, nil
+}
@@ -0,0 +1,55 @@
+package solomachine
+
+import (
+	"go.uber.org/zap"
+)
+
+// FlushResult counts what a flush relayed in each direction
+type FlushResult struct {
+	// Received are the packets from the chain the solo machine received
+	Received int
+	// Relayed are the solo machine packets relayed to the chain again, Acknowledged and TimedOut are the ones processed
+	Relayed      int
+	Acknowledged int
+	TimedOut     int
+}
+
+// Flush relays everything that has not been relayed between the chain and the solo machine: the packets from the chain (and the
+// acknowledgements of the ones already received), and the solo machine packets waiting for acknowledgement. Those are acknowledged
+// if the chain received them, timed out if it no longer can, and otherwise relayed to the chain again.
+func (sm *SoloMachine) Flush(chainName string) (FlushResult, error) {
+	var result FlushResult
+
+	var err error
+	result.Received, err = sm.ReceivePackets(chainName)
+	if err != nil {
+		return result, err
+	}
+
+	pending, err := sm.processPendingPackets(chainName)
+	if err != nil {
+		return result, err
+	}
+	result.Acknowledged = pending.acknowledged
+	result.TimedOut = pending.timedOut
+
+	for start := 0; start < len(pending.unreceived); start += DefaultTransferBatchSize {
+		packets := pending.unreceived[start:min(start+DefaultTransferBatchSize, len(pending.unreceived))]
+		acknowledged, err := sm.relayPackets(chainName, packets, pending.proofHeight)
+		if err != nil {
+			return result, err
+		}
+		result.Relayed += len(packets)
+		result.Acknowledged += acknowledged
+	}
+
+	sm.logger.Info("Flushed packets",
+		zap.String("chain", chainName),
+		zap.Int("received", result.Received),
+		zap.Int("relayed", result.Relayed),
+		zap.Int("acknowledged", result.Acknowledged),
+		zap.Int("timed-out", result.TimedOut),
+	)
+
+	return result, nil
+}
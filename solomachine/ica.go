@@ -0,0 +1,193 @@
+package solomachine
+
+import (
+	"fmt"
+	"github.com/cosmos/gogoproto/proto"
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"go.uber.org/zap"
+	"time"
+)
+
+// InterchainAccountTxOptions are the optional parts of a packet that executes messages with an interchain account
+type InterchainAccountTxOptions struct {
+	PacketTimeoutOptions
+	Memo string
+}
+
+func DefaultInterchainAccountTxOptions() InterchainAccountTxOptions {
+	return InterchainAccountTxOptions{
+		PacketTimeoutOptions: PacketTimeoutOptions{TimeoutHeightOffset: DefaultTimeoutHeightOffset},
+	}
+}
+
+// RegisterInterchainAccount opens a channel with the given ordering from the icacontroller-<owner> port to the interchain accounts host
+// on the chain, which registers the interchain account of the owner, and returns the address of the account on the chain.
+// Running it again resumes an interrupted handshake, or just returns the address if the account is registered. If the channel was
+// closed by a timeout, a new channel (with the same ordering) is opened to the same account.
+func (sm *SoloMachine) RegisterInterchainAccount(chainName string, owner string, order channeltypes.Order) (string, error) {
+	portID, err := icatypes.NewControllerPortID(owner)
+	if err != nil {
+		return "", err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if chainStorage.ConnectionID() == "" || chainStorage.CounterpartyConnectionID() == "" {
+		return "", fmt.Errorf("no connection for chain %s", chainName)
+	}
+
+	channelID, ok := chainStorage.InterchainAccountChannelID(portID)
+	channel, _ := chainStorage.Channel(portID, channelID)
+	if channel.State != channeltypes.OPEN {
+		if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
+			return "", err
+		}
+	}
+
+	if ok && channel.State == channeltypes.CLOSED {
+		// The host only opens a new channel for the account once the end of the old one on the chain is closed too
+		counterpartyChannel, err := sm.r.QueryChannel(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+		if err != nil {
+			return "", err
+		}
+		if counterpartyChannel.State != channeltypes.CLOSED {
+			if err := sm.channelCloseConfirm(chainName, portID, channelID); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if !ok || channel.State == channeltypes.CLOSED {
+		channelID, err = sm.chanOpenInit(chainName, portID, icatypes.HostPortID, order, "")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	chainStorage = sm.storage.GetChainStorage(chainName)
+	channel, _ = chainStorage.Channel(portID, channelID)
+	if channel.State == channeltypes.INIT {
+		if channel.Counterparty.ChannelId == "" {
+			if err := sm.channelOpenTry(chainName, portID, channelID); err != nil {
+				return "", err
+			}
+		}
+		if err := sm.chanOpenAck(chainName, portID, channelID); err != nil {
+			return "", err
+		}
+	}
+
+	chainStorage = sm.storage.GetChainStorage(chainName)
+	channel, _ = chainStorage.Channel(portID, channelID)
+	counterpartyChannel, err := sm.r.QueryChannel(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+	if err != nil {
+		return "", err
+	}
+	if counterpartyChannel.State == channeltypes.TRYOPEN {
+		if err := sm.channelOpenConfirm(chainName, portID, channelID); err != nil {
+			return "", err
+		}
+	}
+
+	address, ok := chainStorage.InterchainAccountAddress(portID)
+	if !ok {
+		return "", fmt.Errorf("no interchain account address for port %s", portID)
+	}
+	sm.logger.Info("Interchain account registered", zap.String("chain", chainName), zap.String("owner", owner), zap.String("address", address), zap.String("channel-id", channelID))
+
+	return address, nil
+}
+
+// SendInterchainAccountTx sends the messages to the interchain account of the owner, to be executed on the chain (all or none of them),
+// and returns the acknowledgement the chain wrote for the packet. The result of a successful acknowledgement is an sdk.TxMsgData.
+// The timeouts and the memo of the packet come from the options.
+func (sm *SoloMachine) SendInterchainAccountTx(chainName string, owner string, msgs []proto.Message, options InterchainAccountTxOptions) (channeltypes.Acknowledgement, error) {
+	portID, err := icatypes.NewControllerPortID(owner)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	channelID, ok := chainStorage.InterchainAccountChannelID(portID)
+	channel, _ := chainStorage.Channel(portID, channelID)
+	if ok && channel.State == channeltypes.CLOSED {
+		return channeltypes.Acknowledgement{}, fmt.Errorf("the channel of the interchain account of %s on chain %s is closed, register it again to open a new channel", owner, chainName)
+	}
+	if !ok || channel.State != channeltypes.OPEN {
+		return channeltypes.Acknowledgement{}, fmt.Errorf("no interchain account registered for %s on chain %s", owner, chainName)
+	}
+
+	metadata, err := icatypes.MetadataFromVersion(channel.Version)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	data, err := icatypes.SerializeCosmosTx(sm.cdc, msgs, metadata.Encoding)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	packetData := icatypes.InterchainAccountPacketData{
+		Type: icatypes.EXECUTE_TX,
+		Data: data,
+		Memo: options.Memo,
+	}
+	if err := packetData.ValidateBasic(); err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	// Give some time for stuff to update
+	time.Sleep(5 * time.Second)
+
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	timeoutHeight, timeoutTimestamp, err := sm.packetTimeout(chainName, lightClientState.LatestHeight, options.PacketTimeoutOptions)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	packet := channeltypes.NewPacket(
+		packetData.GetBytes(),
+		chainStorage.NextSequenceSend(portID, channelID),
+		portID,
+		channelID,
+		channel.Counterparty.PortId,
+		channel.Counterparty.ChannelId,
+		timeoutHeight,
+		timeoutTimestamp,
+	)
+	packet, err = sm.sendPacket(chainName, packet)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	commitmentProof, err := sm.GenerateCommitmentProof(chainName, packet, sequence)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	acknowledgement, err := sm.relayPacket(chainName, packet, commitmentProof, lightClientState.LatestHeight)
+	if err != nil {
+		if channel.Ordering == channeltypes.ORDERED {
+			// The chain only receives the packet after the packets sent before it on the channel
+			if commitments, _ := chainStorage.PacketCommitments(portID, channelID); len(commitments) != 0 && commitments[0].Sequence < packet.Sequence {
+				return channeltypes.Acknowledgement{}, fmt.Errorf("packet %d has been sent, but packet %d before it on the ordered channel is waiting for acknowledgement, relay them in order with relay flush: %w", packet.Sequence, commitments[0].Sequence, err)
+			}
+		}
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	return unmarshalAcknowledgement(packet, acknowledgement)
+}
@@ -0,0 +1,140 @@
+package solomachine
+
+import (
+	"fmt"
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	connectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"strings"
+)
+
+var _ IBCModule = icaControllerModule{}
+
+// icaControllerModule is the built-in interchain accounts controller application, bound to all the icacontroller-<owner> ports
+// It checks the channel handshakes the same way as the ibc-go controller, the messages themselves are executed by the host on the chain.
+type icaControllerModule struct {
+	sm *SoloMachine
+}
+
+// validateControllerMetadata checks the metadata the same way as icatypes.ValidateControllerMetadata, against the connection of the solo machine
+func validateControllerMetadata(chainStorage *smstorage.ChainStorage, connectionHops []string, metadata icatypes.Metadata) error {
+	if metadata.Encoding != icatypes.EncodingProtobuf && metadata.Encoding != icatypes.EncodingProto3JSON {
+		return fmt.Errorf("%w: unsupported encoding format %s", icatypes.ErrInvalidCodec, metadata.Encoding)
+	}
+	if metadata.TxType != icatypes.TxTypeSDKMultiMsg {
+		return fmt.Errorf("%w: unsupported transaction type %s", icatypes.ErrUnknownDataType, metadata.TxType)
+	}
+	if len(connectionHops) != 1 || connectionHops[0] != chainStorage.ConnectionID() {
+		return fmt.Errorf("%w: expected connection hops %s, got %s", connectiontypes.ErrInvalidConnection, []string{chainStorage.ConnectionID()}, connectionHops)
+	}
+	if metadata.ControllerConnectionId != chainStorage.ConnectionID() {
+		return fmt.Errorf("%w: expected %s, got %s", connectiontypes.ErrInvalidConnection, chainStorage.ConnectionID(), metadata.ControllerConnectionId)
+	}
+	if metadata.HostConnectionId != chainStorage.CounterpartyConnectionID() {
+		return fmt.Errorf("%w: expected %s, got %s", connectiontypes.ErrInvalidConnection, chainStorage.CounterpartyConnectionID(), metadata.HostConnectionId)
+	}
+	if metadata.Address != "" {
+		if err := icatypes.ValidateAccountAddress(metadata.Address); err != nil {
+			return err
+		}
+	}
+	if metadata.Version != icatypes.Version {
+		return fmt.Errorf("%w: expected %s, got %s", icatypes.ErrInvalidVersion, icatypes.Version, metadata.Version)
+	}
+
+	return nil
+}
+
+// OnChanOpenInit proposes the default metadata if there is no version, and records the channel as the channel of the port,
+// so an interrupted handshake is resumed on the same channel. A closed channel is only replaced by one with the same ordering and metadata.
+func (m icaControllerModule) OnChanOpenInit(chainName string, order channeltypes.Order, connectionHops []string, portID string, channelID string, counterparty channeltypes.Counterparty, version string) (string, error) {
+	chainStorage := m.sm.storage.GetChainStorage(chainName)
+
+	if !strings.HasPrefix(portID, icatypes.ControllerPortPrefix) {
+		return "", fmt.Errorf("%w: expected %s{owner}, got %s", icatypes.ErrInvalidControllerPort, icatypes.ControllerPortPrefix, portID)
+	}
+	if counterparty.PortId != icatypes.HostPortID {
+		return "", fmt.Errorf("%w: expected %s, got %s", icatypes.ErrInvalidHostPort, icatypes.HostPortID, counterparty.PortId)
+	}
+
+	var metadata icatypes.Metadata
+	if strings.TrimSpace(version) == "" {
+		metadata = icatypes.NewDefaultMetadata(chainStorage.ConnectionID(), chainStorage.CounterpartyConnectionID())
+	} else {
+		var err error
+		metadata, err = icatypes.MetadataFromVersion(version)
+		if err != nil {
+			return "", err
+		}
+	}
+	if err := validateControllerMetadata(chainStorage, connectionHops, metadata); err != nil {
+		return "", err
+	}
+
+	if activeChannelID, ok := chainStorage.InterchainAccountChannelID(portID); ok {
+		if activeChannel, ok := chainStorage.Channel(portID, activeChannelID); ok {
+			if activeChannel.State != channeltypes.CLOSED {
+				return "", fmt.Errorf("%w: existing channel %s for port %s must be %s", icatypes.ErrActiveChannelAlreadySet, activeChannelID, portID, channeltypes.CLOSED)
+			}
+			if activeChannel.Ordering != order {
+				return "", fmt.Errorf("%w: order cannot change when reopening a channel, expected %s, got %s", channeltypes.ErrInvalidChannelOrdering, activeChannel.Ordering, order)
+			}
+			if !icatypes.IsPreviousMetadataEqual(activeChannel.Version, metadata) {
+				return "", fmt.Errorf("%w: previous active channel metadata does not match provided version", icatypes.ErrInvalidVersion)
+			}
+		}
+	}
+	chainStorage.SetInterchainAccountChannelID(portID, channelID)
+
+	return string(icatypes.ModuleCdc.MustMarshalJSON(&metadata)), nil
+}
+
+func (icaControllerModule) OnChanOpenTry(_ string, _ channeltypes.Order, _ []string, _ string, _ string, _ channeltypes.Counterparty, _ string) (string, error) {
+	return "", fmt.Errorf("%w: channel handshake must be initiated by the controller", icatypes.ErrInvalidChannelFlow)
+}
+
+// OnChanOpenAck stores the address of the interchain account the host registered, which is in the version of the host
+func (m icaControllerModule) OnChanOpenAck(chainName string, portID string, channelID string, _ string, counterpartyVersion string) error {
+	chainStorage := m.sm.storage.GetChainStorage(chainName)
+
+	metadata, err := icatypes.MetadataFromVersion(counterpartyVersion)
+	if err != nil {
+		return err
+	}
+
+	channel, ok := chainStorage.Channel(portID, channelID)
+	if !ok {
+		return fmt.Errorf("channel %s on port %s not found", channelID, portID)
+	}
+	if err := validateControllerMetadata(chainStorage, channel.ConnectionHops, metadata); err != nil {
+		return err
+	}
+	if strings.TrimSpace(metadata.Address) == "" {
+		return fmt.Errorf("%w: interchain account address cannot be empty", icatypes.ErrInvalidAccountAddress)
+	}
+
+	chainStorage.SetInterchainAccountChannelID(portID, channelID)
+	chainStorage.SetInterchainAccountAddress(portID, metadata.Address)
+
+	return nil
+}
+
+func (icaControllerModule) OnChanOpenConfirm(_ string, _ string, _ string) error {
+	return fmt.Errorf("%w: channel handshake must be initiated by the controller", icatypes.ErrInvalidChannelFlow)
+}
+
+// OnRecvPacket rejects the packet, the host never sends packets to the controller
+func (icaControllerModule) OnRecvPacket(_ string, _ channeltypes.Packet) (exported.Acknowledgement, error) {
+	return channeltypes.NewErrorAcknowledgement(fmt.Errorf("%w: cannot receive packet on controller", icatypes.ErrInvalidChannelFlow)), nil
+}
+
+// OnAcknowledgementPacket does nothing, the result of the messages is in the acknowledgement the sender gets back
+func (icaControllerModule) OnAcknowledgementPacket(_ string, _ channeltypes.Packet, _ []byte) error {
+	return nil
+}
+
+func (icaControllerModule) OnTimeoutPacket(_ string, _ channeltypes.Packet) error {
+	return nil
+}
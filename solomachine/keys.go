@@ -0,0 +1,265 @@
+package solomachine
+
+import (
+	"fmt"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func (sm *SoloMachine) newSigner(config signer.Config) (signer.Signer, error) {
+	switch config.Type {
+	case "", signer.TypeLocal:
+		privKey, err := sm.unlockLocalKey(config)
+		if err != nil {
+			return nil, err
+		}
+		return signer.NewLocalSigner(privKey), nil
+	case signer.TypeKeyring:
+		kr, err := utils.GetKeyring(config.KeyringBackend, sm.homedir, sm.cdc)
+		if err != nil {
+			return nil, err
+		}
+		return signer.NewKeyringSigner(kr, config.KeyName)
+	case signer.TypeFile:
+		return signer.NewFileSigner(sm.cdc, sm.homePath(config.KeyFile))
+	case signer.TypeOffline:
+		return signer.NewOfflineSigner(sm.cdc, config.PublicKey)
+	case signer.TypePKCS11:
+		pinFile := ""
+		if config.PinFile != "" {
+			pinFile = sm.homePath(config.PinFile)
+		}
+		pin, err := signer.ReadPKCS11Pin(pinFile)
+		if err != nil {
+			return nil, err
+		}
+		return signer.NewPKCS11Signer(config.Module, config.Slot, pin, config.KeyLabel)
+	case signer.TypeMultisig:
+		members := make([]signer.Signer, len(config.Members))
+		for i, memberConfig := range config.Members {
+			member, err := sm.newSigner(memberConfig)
+			if err != nil {
+				return nil, fmt.Errorf("multisig member %d: %w", i, err)
+			}
+			members[i] = member
+		}
+		return signer.NewMultiSigner(sm.logger, config.Threshold, members)
+	default:
+		return nil, fmt.Errorf("unknown signer type: %s", config.Type)
+	}
+}
+
+// unlockLocalKey reads the passphrase and decrypts the local signer key with it
+// The key is created with the configured key algorithm if it does not exist yet.
+func (sm *SoloMachine) unlockLocalKey(config signer.Config) (cryptotypes.PrivKey, error) {
+	keyExists := sm.storage.HasLocalPrivateKey()
+	if keyExists && config.KeyAlgorithm != "" && config.KeyAlgorithm != sm.storage.LocalKeyAlgorithm() {
+		return nil, fmt.Errorf("the solo machine key already exists with key algorithm %s, it cannot be changed to %s", sm.storage.LocalKeyAlgorithm(), config.KeyAlgorithm)
+	}
+
+	passphrase, err := sm.keyPassphrase(!keyExists)
+	if err != nil {
+		return nil, err
+	}
+
+	if !keyExists {
+		keyAlgo := config.KeyAlgorithm
+		if keyAlgo == "" {
+			keyAlgo = signer.DefaultKeyAlgo
+		}
+
+		newPrivKey, mnemonic, err := signer.GenerateKey(keyAlgo)
+		if err != nil {
+			return nil, err
+		}
+		if err := sm.storage.CreateLocalPrivateKey(newPrivKey, mnemonic, passphrase); err != nil {
+			return nil, err
+		}
+		sm.logger.Info("Created new solo machine key", zap.String("key-algorithm", keyAlgo))
+		printMnemonic(mnemonic)
+	}
+
+	privKey, err := sm.storage.LocalPrivateKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	sm.localKeyUnlocked = true
+
+	return privKey, nil
+}
+
+// keyPassphrase returns the passphrase the local keys are encrypted with, it is only read once
+// confirm asks for the passphrase twice when it is prompted for, it should be set when the passphrase is used for the first time.
+func (sm *SoloMachine) keyPassphrase(confirm bool) (string, error) {
+	if sm.passphraseRead {
+		return sm.localKeyPassphrase, nil
+	}
+
+	passphrase, err := signer.ReadPassphrase(sm.passphraseFile, confirm)
+	if err != nil {
+		return "", err
+	}
+	sm.localKeyPassphrase = passphrase
+	sm.passphraseRead = true
+
+	return passphrase, nil
+}
+
+// printMnemonic shows the mnemonic of a newly generated key to the user (on stderr, so it does not end up in piped output)
+func printMnemonic(mnemonic string) {
+	fmt.Fprintf(os.Stderr, "\n**Important** write this mnemonic phrase in a safe place.\nIt is the only way to recover the solo machine key if the home directory is lost (besides keys solo-machine export).\n\n%s\n\n", mnemonic)
+}
+
+// ChangeLocalKeyPassphrase re-encrypts the local signer key with a new passphrase
+func (sm *SoloMachine) ChangeLocalKeyPassphrase(newPassphrase string) error {
+	if !sm.localKeyUnlocked {
+		return fmt.Errorf("the local signer key is not in use")
+	}
+
+	if err := sm.storage.ChangeLocalKeyPassphrase(sm.localKeyPassphrase, newPassphrase); err != nil {
+		return err
+	}
+	sm.localKeyPassphrase = newPassphrase
+
+	return nil
+}
+
+// homePath resolves paths relative to the home directory
+func (sm *SoloMachine) homePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(sm.homedir, path)
+}
+
+// RotateKey replaces the local signer key with a new key on all the chains that have a counterparty light client
+// The new key is first stored as pending, then installed on every counterparty solo machine client with a header signed by the current key.
+// A chain is signed with the new key as soon as its client has it. Only once all the chains have confirmed the new key, it replaces
+// the current key in storage. If the rotation fails halfway, running it again will continue with the same pending key (newPrivKey is
+// only used if there is no pending key). The configured chains are given, since a chain with a client that is no longer configured
+// cannot get the new key, and would be left with a key the solo machine no longer has: the rotation fails for it instead.
+func (sm *SoloMachine) RotateKey(configuredChainNames []string, newPrivKey cryptotypes.PrivKey) error {
+	if _, ok := sm.signer.(*signer.LocalSigner); !ok {
+		return fmt.Errorf("key rotation is only supported for the local signer, got %T", sm.signer)
+	}
+
+	pendingPrivKey, ok, err := sm.storage.PendingLocalPrivateKey(sm.localKeyPassphrase)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if newPrivKey != nil && !newPrivKey.Equals(pendingPrivKey) {
+			return fmt.Errorf("a different key rotation is already in progress, finish it before rotating to another key")
+		}
+		sm.logger.Info("Continuing key rotation in progress")
+	} else {
+		var mnemonic string
+		if newPrivKey == nil {
+			// The new key uses the same algorithm as the current key
+			newPrivKey, mnemonic, err = signer.GenerateKey(sm.storage.LocalKeyAlgorithm())
+			if err != nil {
+				return err
+			}
+		}
+		if err := sm.storage.SetPendingLocalPrivateKey(newPrivKey, mnemonic, sm.localKeyPassphrase); err != nil {
+			return err
+		}
+		if mnemonic != "" {
+			printMnemonic(mnemonic)
+		}
+		pendingPrivKey = newPrivKey
+	}
+	newPubKey := pendingPrivKey.PubKey()
+
+	// The clients created before the chains with a client were listed are only found through the config
+	chainNames := slices.Clone(configuredChainNames)
+	for _, chainName := range sm.storage.ClientChainNames() {
+		if slices.Contains(chainNames, chainName) {
+			continue
+		}
+		if !sm.storage.GetChainStorage(chainName).HasPrivateKey() {
+			return fmt.Errorf("chain %s has a solo machine client with the current key but is not in the config, add it back to rotate its key too", chainName)
+		}
+	}
+
+	for _, chainName := range chainNames {
+		if !sm.CounterpartyLightClientExists(chainName) {
+			sm.logger.Info("No counterparty light client, skipping key rotation", zap.String("chain", chainName))
+			continue
+		}
+		chainStorage := sm.storage.GetChainStorage(chainName)
+		if chainStorage.HasPrivateKey() {
+			sm.logger.Info("Chain has its own key, skipping key rotation", zap.String("chain", chainName))
+			continue
+		}
+
+		if err := sm.installPubKey(chainName, newPubKey); err != nil {
+			return err
+		}
+		chainStorage.SetInstalledPendingKey(newPubKey.Bytes())
+		sm.chainSigners[chainName] = signer.NewLocalSigner(pendingPrivKey)
+	}
+
+	promotedPrivKey, err := sm.storage.PromotePendingLocalPrivateKey(sm.localKeyPassphrase)
+	if err != nil {
+		return err
+	}
+	sm.signer = signer.NewLocalSigner(promotedPrivKey)
+	for _, chainName := range chainNames {
+		if !sm.storage.GetChainStorage(chainName).HasPrivateKey() {
+			delete(sm.chainSigners, chainName)
+		}
+	}
+	sm.logger.Info("Key rotation finished, the new key is now active")
+
+	return nil
+}
+
+// installPubKey installs the new public key on the counterparty solo machine client, with a header signed by the current key of the chain
+// Nothing is done if the client already has the new key (from an earlier, interrupted, rotation).
+func (sm *SoloMachine) installPubKey(chainName string, newPubKey cryptotypes.PubKey) error {
+	rotated, err := sm.counterpartyHasPubKey(chainName, newPubKey)
+	if err != nil {
+		return err
+	}
+	if rotated {
+		sm.logger.Info("Counterparty light client already has the new key", zap.String("chain", chainName))
+		return nil
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	header, err := sm.createSoloMachineHeader(chainName, newPubKey, chainStorage.Diversifier())
+	if err != nil {
+		return err
+	}
+	if err := sm.r.UpdateClient(chainName, chainStorage.CounterpartyClientID(), header); err != nil {
+		return fmt.Errorf("failed to install the new key on chain %s (run the rotation again to continue): %w", chainName, err)
+	}
+
+	sm.logger.Info("Installed new key on counterparty light client", zap.String("chain", chainName))
+
+	return nil
+}
+
+// counterpartyHasPubKey checks if the counterparty solo machine client has the public key in its consensus state
+func (sm *SoloMachine) counterpartyHasPubKey(chainName string, pubKey cryptotypes.PubKey) (bool, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	clientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return false, err
+	}
+
+	var consensusPubKey cryptotypes.PubKey
+	if err := sm.cdc.UnpackAny(clientState.ConsensusState.PublicKey, &consensusPubKey); err != nil {
+		return false, err
+	}
+
+	return consensusPubKey.Equals(pubKey), nil
+}
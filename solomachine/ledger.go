@@ -0,0 +1,197 @@
+package solomachine
+
+import (
+	sdkmath "cosmossdk.io/math"
+	"encoding/json"
+	"errors"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"go.uber.org/zap"
+	"strings"
+)
+
+var errExternalLedger = errors.New("the built-in ledger is not used when an external state machine is configured")
+
+// usesBuiltInLedger checks if the ICS20 packets are handled by the built-in transfer application, which keeps the tokens in the ledger
+func (sm *SoloMachine) usesBuiltInLedger() bool {
+	module, _ := sm.router.GetRoute(transfertypes.PortID)
+	_, ok := module.(transferModule)
+	return ok
+}
+
+// Mint creates tokens in a local account, there is no authorization beyond having access to the solo machine
+func (sm *SoloMachine) Mint(account string, coin sdk.Coin) error {
+	if !sm.usesBuiltInLedger() {
+		return errExternalLedger
+	}
+	if err := smstorage.ValidateAccount(account); err != nil {
+		return err
+	}
+	if err := sm.storage.MintCoins(account, coin); err != nil {
+		return err
+	}
+	sm.storage.Commit()
+
+	sm.logger.Info("Minted tokens", zap.String("account", account), zap.String("amount", coin.String()))
+
+	return nil
+}
+
+// Burn destroys tokens in a local account, there is no authorization beyond having access to the solo machine
+func (sm *SoloMachine) Burn(account string, coin sdk.Coin) error {
+	if !sm.usesBuiltInLedger() {
+		return errExternalLedger
+	}
+	if err := smstorage.ValidateAccount(account); err != nil {
+		return err
+	}
+	if err := sm.storage.BurnCoins(account, coin); err != nil {
+		return err
+	}
+	sm.storage.Commit()
+
+	sm.logger.Info("Burned tokens", zap.String("account", account), zap.String("amount", coin.String()))
+
+	return nil
+}
+
+// Balances returns the balances of a local account
+func (sm *SoloMachine) Balances(account string) (sdk.Coins, error) {
+	if !sm.usesBuiltInLedger() {
+		return nil, errExternalLedger
+	}
+	if err := smstorage.ValidateAccount(account); err != nil {
+		return nil, err
+	}
+
+	return sm.storage.Balances(account), nil
+}
+
+// The token movements of ICS20 packets below follow the transfer keeper. They are not committed on their own,
+// but together with the packet state change they belong to.
+
+// sendTransferTokens takes the tokens of an outgoing ICS20 packet from the sender: they are escrowed if the solo machine is
+// the source of the denom, and burned if they are vouchers returning to their source
+func (sm *SoloMachine) sendTransferTokens(packet channeltypes.Packet) error {
+	data, coin, err := transferPacketData(packet)
+	if err != nil {
+		return err
+	}
+	if err := smstorage.ValidateAccount(data.Sender); err != nil {
+		return err
+	}
+
+	if transfertypes.SenderChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		return sm.storage.SendCoins(data.Sender, smstorage.EscrowAccount(packet.SourcePort, packet.SourceChannel), coin)
+	}
+
+	return sm.storage.BurnCoins(data.Sender, coin)
+}
+
+// refundTransferTokens gives the tokens of an outgoing ICS20 packet back to the sender, after a timeout or an error acknowledgement
+func (sm *SoloMachine) refundTransferTokens(chainName string, packet channeltypes.Packet) error {
+	data, coin, err := transferPacketData(packet)
+	if err != nil {
+		return err
+	}
+
+	if transfertypes.SenderChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		err = sm.storage.SendCoins(smstorage.EscrowAccount(packet.SourcePort, packet.SourceChannel), data.Sender, coin)
+	} else {
+		err = sm.storage.MintCoins(data.Sender, coin)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to refund packet %d: %w", packet.Sequence, err)
+	}
+
+	sm.logger.Info("Refunded tokens", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence), zap.String("sender", data.Sender), zap.String("amount", coin.String()))
+
+	return nil
+}
+
+// receiveTransferTokens gives the tokens of an incoming ICS20 packet to the receiver: they are unescrowed if the solo machine is
+// the source of the denom, otherwise vouchers are minted
+func (sm *SoloMachine) receiveTransferTokens(packet channeltypes.Packet, data transfertypes.FungibleTokenPacketData) error {
+	if err := smstorage.ValidateAccount(data.Receiver); err != nil {
+		return err
+	}
+	amount, ok := sdkmath.NewIntFromString(data.Amount)
+	if !ok {
+		return fmt.Errorf("invalid amount %s", data.Amount)
+	}
+
+	if transfertypes.ReceiverChainIsSource(packet.SourcePort, packet.SourceChannel, data.Denom) {
+		// The tokens are returning, what is left after removing the prefix is the denom they were sent with (a native denom or another voucher)
+		unprefixedDenom := data.Denom[len(transfertypes.GetDenomPrefix(packet.SourcePort, packet.SourceChannel)):]
+		denom := transfertypes.ParseDenomTrace(unprefixedDenom).IBCDenom()
+		return sm.storage.SendCoins(smstorage.EscrowAccount(packet.DestinationPort, packet.DestinationChannel), data.Receiver, sdk.Coin{Denom: denom, Amount: amount})
+	}
+
+	denomTrace := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(packet.DestinationPort, packet.DestinationChannel, data.Denom))
+	if err := denomTrace.Validate(); err != nil {
+		return err
+	}
+	if err := sm.storage.MintCoins(data.Receiver, sdk.Coin{Denom: denomTrace.IBCDenom(), Amount: amount}); err != nil {
+		return err
+	}
+	if !sm.storage.HasDenomTrace(denomTrace.Hash()) {
+		sm.storage.SetDenomTrace(denomTrace)
+	}
+
+	return nil
+}
+
+// DenomTraces returns the denom traces of all the vouchers the solo machine has received
+func (sm *SoloMachine) DenomTraces() transfertypes.Traces {
+	return sm.storage.DenomTraces()
+}
+
+// DenomTrace returns the denom trace of a voucher, given as ibc/<hash> or just the hash
+func (sm *SoloMachine) DenomTrace(denom string) (transfertypes.DenomTrace, error) {
+	hash, err := transfertypes.ParseHexHash(strings.TrimPrefix(denom, transfertypes.DenomPrefix+"/"))
+	if err != nil {
+		return transfertypes.DenomTrace{}, fmt.Errorf("invalid denom trace hash %s: %w", denom, err)
+	}
+
+	denomTrace, ok := sm.storage.DenomTrace(hash)
+	if !ok {
+		return transfertypes.DenomTrace{}, fmt.Errorf("no denom trace for %s", denom)
+	}
+
+	return denomTrace, nil
+}
+
+// fullDenomPath turns the denom of local tokens into the denom sent in ICS20 packets: vouchers (ibc/<hash>) are sent with
+// their full denom path, so that the chain recognizes them as returning tokens
+func (sm *SoloMachine) fullDenomPath(denom string) (string, error) {
+	if err := transfertypes.ValidateIBCDenom(denom); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(denom, transfertypes.DenomPrefix+"/") {
+		return denom, nil
+	}
+
+	denomTrace, err := sm.DenomTrace(denom)
+	if err != nil {
+		return "", err
+	}
+
+	return denomTrace.GetFullDenomPath(), nil
+}
+
+// transferPacketData decodes the ICS20 packet data, and returns the tokens of the packet with their local denom
+func transferPacketData(packet channeltypes.Packet) (transfertypes.FungibleTokenPacketData, sdk.Coin, error) {
+	var data transfertypes.FungibleTokenPacketData
+	if err := json.Unmarshal(packet.GetData(), &data); err != nil {
+		return transfertypes.FungibleTokenPacketData{}, sdk.Coin{}, fmt.Errorf("cannot unmarshal ICS-20 transfer packet data of packet %d: %w", packet.Sequence, err)
+	}
+	amount, ok := sdkmath.NewIntFromString(data.Amount)
+	if !ok {
+		return transfertypes.FungibleTokenPacketData{}, sdk.Coin{}, fmt.Errorf("invalid amount %s in packet %d", data.Amount, packet.Sequence)
+	}
+
+	return data, sdk.Coin{Denom: transfertypes.ParseDenomTrace(data.Denom).IBCDenom(), Amount: amount}, nil
+}
@@ -0,0 +1,475 @@
+package solomachine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
+	"go.uber.org/zap"
+	"os"
+	"time"
+)
+
+const (
+	OfflineOperationUpdate     = "update"
+	OfflineOperationTransfer   = "transfer"
+	OfflineOperationConnection = "connection-open-ack"
+	OfflineOperationChannel    = "channel-open-ack"
+)
+
+// offlineOperationEntries is the number of sign bytes each offline operation needs signed
+var offlineOperationEntries = map[string]int{
+	OfflineOperationUpdate:     1,
+	OfflineOperationTransfer:   2,
+	OfflineOperationConnection: 3,
+	OfflineOperationChannel:    1,
+}
+
+// OfflineSigningFile is the portable file used to move sign bytes to an offline signer and the signatures back
+type OfflineSigningFile struct {
+	ChainName string                `json:"chain_name"`
+	Operation string                `json:"operation"`
+	Entries   []OfflineSigningEntry `json:"entries"`
+
+	// Operation specific payloads needed to finish the relay after signing
+	Packet      []byte `json:"packet,omitempty"`       // transfer: the proto encoded packet
+	ClientState []byte `json:"client_state,omitempty"` // connection-open-ack: the proto encoded tendermint client state that is proven
+}
+
+// OfflineSigningEntry is a single solo machine SignBytes that needs to be signed, in the order they are needed
+type OfflineSigningEntry struct {
+	Description string `json:"description"`
+	SignBytes   []byte `json:"sign_bytes"`          // the proto encoded solo machine SignBytes
+	Signature   []byte `json:"signature,omitempty"` // filled in by the offline signer
+}
+
+func ReadOfflineSigningFile(path string) (*OfflineSigningFile, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file OfflineSigningFile
+	if err := json.Unmarshal(bz, &file); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func WriteOfflineSigningFile(file *OfflineSigningFile, path string) error {
+	bz, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, bz, 0644)
+}
+
+// ExportOfflineUpdate exports the sign bytes for a header that updates the counterparty light client
+func (sm *SoloMachine) ExportOfflineUpdate(chainName string) (*OfflineSigningFile, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &OfflineSigningFile{
+		ChainName: chainName,
+		Operation: OfflineOperationUpdate,
+	}
+
+	pubKey, err := sm.ChainPublicKey(chainName)
+	if err != nil {
+		return nil, err
+	}
+	headerSignBytes, _, err := sm.soloMachineHeaderSignBytes(chainName, sequence, pubKey, chainStorage.Diversifier())
+	if err != nil {
+		return nil, err
+	}
+	if err := sm.addOfflineEntry(file, "counterparty light client header", headerSignBytes); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ExportOfflineTransfer exports the sign bytes for an ICS20 transfer (a header update followed by the packet commitment)
+func (sm *SoloMachine) ExportOfflineTransfer(chainName string, sender string, receiver string, denom string, amount uint64, options TransferOptions) (*OfflineSigningFile, error) {
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return nil, err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := sm.ChainPublicKey(chainName)
+	if err != nil {
+		return nil, err
+	}
+	headerSignBytes, _, err := sm.soloMachineHeaderSignBytes(chainName, sequence, pubKey, chainStorage.Diversifier())
+	if err != nil {
+		return nil, err
+	}
+
+	sequence++ // The header update increments the sequence
+
+	// Reserved, so packets sent (or exported) before this one is imported get other sequences
+	packetSequence := chainStorage.ReserveSendSequence(transfertypes.PortID, chainStorage.ICS20ChannelID())
+	packet, err := sm.newTransferPacket(chainName, lightClientState.LatestHeight, packetSequence, sender, receiver, denom, amount, options)
+	if err != nil {
+		return nil, err
+	}
+	packetBz, err := sm.cdc.Marshal(&packet)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &OfflineSigningFile{
+		ChainName: chainName,
+		Operation: OfflineOperationTransfer,
+		Packet:    packetBz,
+	}
+	if err := sm.addOfflineEntry(file, "counterparty light client header", headerSignBytes); err != nil {
+		return nil, err
+	}
+	if err := sm.addOfflineEntry(file, fmt.Sprintf("packet commitment for %d%s from %s to %s", amount, denom, sender, receiver), sm.commitmentSignBytes(chainName, packet, sequence)); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ExportOfflineConnectionOpenAck initializes the connection (which does not need any signatures) and exports the sign bytes for the proofs needed by connection open ack
+func (sm *SoloMachine) ExportOfflineConnectionOpenAck(chainName string) (*OfflineSigningFile, error) {
+	open, err := sm.initConnection(chainName)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, fmt.Errorf("connection is already open for chain %s", chainName)
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return nil, err
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return nil, err
+	}
+
+	tryProofSignBytes, err := sm.connOpenTrySignBytes(chainName, sequence)
+	if err != nil {
+		return nil, err
+	}
+	clientProofSignBytes, err := sm.clientStateSignBytes(chainName, sequence+1, lightClientState)
+	if err != nil {
+		return nil, err
+	}
+	consensusProofSignBytes, err := sm.consensusStateSignBytes(chainName, sequence+2, lightClientState)
+	if err != nil {
+		return nil, err
+	}
+
+	clientStateBz, err := sm.cdc.Marshal(lightClientState)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &OfflineSigningFile{
+		ChainName:   chainName,
+		Operation:   OfflineOperationConnection,
+		ClientState: clientStateBz,
+	}
+	if err := sm.addOfflineEntry(file, "connection open try proof", tryProofSignBytes); err != nil {
+		return nil, err
+	}
+	if err := sm.addOfflineEntry(file, "client state proof", clientProofSignBytes); err != nil {
+		return nil, err
+	}
+	if err := sm.addOfflineEntry(file, "consensus state proof", consensusProofSignBytes); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ExportOfflineChannelOpenAck initializes the ICS20 channel (which does not need any signatures) and exports the sign bytes for the proof needed by channel open ack
+func (sm *SoloMachine) ExportOfflineChannelOpenAck(chainName string) (*OfflineSigningFile, error) {
+	open, err := sm.initICS20Channel(chainName)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, fmt.Errorf("ICS20 channel is already open for chain %s", chainName)
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return nil, err
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	tryProofSignBytes, err := sm.chanOpenTrySignBytes(chainName, transfertypes.PortID, chainStorage.ICS20ChannelID(), sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &OfflineSigningFile{
+		ChainName: chainName,
+		Operation: OfflineOperationChannel,
+	}
+	if err := sm.addOfflineEntry(file, "channel open try proof", tryProofSignBytes); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// SignOffline signs all the entries in the file with the solo machine signer (meant to be run on the offline machine)
+func (sm *SoloMachine) SignOffline(file *OfflineSigningFile) error {
+	for i, entry := range file.Entries {
+		var signBytes solomachineclient.SignBytes
+		if err := sm.cdc.Unmarshal(entry.SignBytes, &signBytes); err != nil {
+			return fmt.Errorf("invalid sign bytes in entry %d: %w", i, err)
+		}
+
+		sm.logger.Info("Signing",
+			zap.String("chain-name", file.ChainName),
+			zap.String("operation", file.Operation),
+			zap.String("description", entry.Description),
+			zap.Uint64("sequence", signBytes.Sequence),
+			zap.String("diversifier", signBytes.Diversifier),
+			zap.String("path", string(signBytes.Path)),
+		)
+
+		sig, err := sm.GenerateSignature(file.ChainName, entry.SignBytes)
+		if err != nil {
+			return err
+		}
+		file.Entries[i].Signature = sig
+	}
+
+	return nil
+}
+
+// ImportOffline takes the signatures from a signed file and finishes the operation it was exported for
+func (sm *SoloMachine) ImportOffline(file *OfflineSigningFile) error {
+	expectedEntries, ok := offlineOperationEntries[file.Operation]
+	if !ok {
+		return fmt.Errorf("unknown offline operation: %s", file.Operation)
+	}
+	if len(file.Entries) != expectedEntries {
+		return fmt.Errorf("offline operation %s needs %d entries, got %d", file.Operation, expectedEntries, len(file.Entries))
+	}
+
+	signBytes := make([]solomachineclient.SignBytes, len(file.Entries))
+	for i, entry := range file.Entries {
+		if len(entry.Signature) == 0 {
+			return fmt.Errorf("entry %d (%s) has not been signed", i, entry.Description)
+		}
+		if err := sm.cdc.Unmarshal(entry.SignBytes, &signBytes[i]); err != nil {
+			return fmt.Errorf("invalid sign bytes in entry %d: %w", i, err)
+		}
+	}
+
+	chainStorage := sm.storage.GetChainStorage(file.ChainName)
+	clientState, err := sm.r.GetClientState(file.ChainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return err
+	}
+
+	if err := sm.checkOfflineEntries(file, signBytes, clientState); err != nil {
+		return err
+	}
+
+	switch file.Operation {
+	case OfflineOperationUpdate:
+		return sm.importOfflineHeader(file.ChainName, &signBytes[0], file.Entries[0].Signature)
+	case OfflineOperationTransfer:
+		if err := sm.importOfflineHeader(file.ChainName, &signBytes[0], file.Entries[0].Signature); err != nil {
+			return err
+		}
+		if err := sm.UpdateLightClient(file.ChainName); err != nil {
+			return err
+		}
+
+		// Give some time for stuff to update
+		time.Sleep(5 * time.Second)
+
+		var packet channeltypes.Packet
+		if err := sm.cdc.Unmarshal(file.Packet, &packet); err != nil {
+			return err
+		}
+		sentPacket, err := sm.applySendPacket(file.ChainName, packet)
+		if err != nil {
+			return err
+		}
+		// Checked before the packet is stored, so a packet the signature does not cover is reverted instead of left pending
+		if !bytes.Equal(sentPacket.Data, packet.Data) {
+			return sm.revertSendPackets(file.ChainName, []channeltypes.Packet{sentPacket}, fmt.Errorf("the state machine changed the data of the packet, which invalidates the offline signature"))
+		}
+		if err := sm.storeSentPacket(file.ChainName, packet); err != nil {
+			return err
+		}
+		commitmentProof, err := sm.proofFromSignature(file.Entries[1].Signature, signBytes[1].Timestamp)
+		if err != nil {
+			return err
+		}
+		lightClientState, err := chainStorage.LightClientState()
+		if err != nil {
+			return err
+		}
+
+		acknowledgement, err := sm.relayPacket(file.ChainName, packet, commitmentProof, lightClientState.LatestHeight)
+		if err != nil {
+			return err
+		}
+		ack, err := unmarshalAcknowledgement(packet, acknowledgement)
+		if err != nil {
+			return err
+		}
+		if !ack.Success() {
+			return fmt.Errorf("the chain rejected the transfer: %s", ack.GetError())
+		}
+
+		return nil
+	case OfflineOperationConnection:
+		var clientState tmclient.ClientState
+		if err := sm.cdc.Unmarshal(file.ClientState, &clientState); err != nil {
+			return err
+		}
+
+		proofs := make([][]byte, len(file.Entries))
+		for i, entry := range file.Entries {
+			proofs[i], err = sm.proofFromSignature(entry.Signature, signBytes[i].Timestamp)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := sm.r.ConnectionOpenAck(
+			file.ChainName,
+			chainStorage.CounterpartyConnectionID(),
+			chainStorage.ConnectionID(),
+			&clientState,
+			proofs[0],
+			proofs[1],
+			proofs[2],
+			clientState.LatestHeight,
+		); err != nil {
+			return err
+		}
+
+		return sm.UpdateLightClient(file.ChainName)
+	case OfflineOperationChannel:
+		tryProof, err := sm.proofFromSignature(file.Entries[0].Signature, signBytes[0].Timestamp)
+		if err != nil {
+			return err
+		}
+		lightClientState, err := chainStorage.LightClientState()
+		if err != nil {
+			return err
+		}
+
+		return sm.channelOpenAck(file.ChainName, transfertypes.PortID, chainStorage.ICS20ChannelID(), tryProof, lightClientState)
+	default:
+		return fmt.Errorf("unknown offline operation: %s", file.Operation)
+	}
+}
+
+// importOfflineHeader rebuilds the header from the signed header sign bytes and updates the counterparty light client with it
+// checkOfflineEntries checks that the signed entries are the ones that are needed now, so an edited (or outdated) file is rejected
+// before anything is submitted. Every entry uses the next sequence of the counterparty client and the diversifier it verifies with
+// (none of the operations change it), and the commitment of a transfer has to be the commitment of the packet in the file.
+func (sm *SoloMachine) checkOfflineEntries(file *OfflineSigningFile, signBytes []solomachineclient.SignBytes, clientState *solomachineclient.ClientState) error {
+	if signBytes[0].Sequence != clientState.Sequence {
+		return fmt.Errorf("the counterparty light client sequence has changed since the export (exported at %d, now at %d), please export again", signBytes[0].Sequence, clientState.Sequence)
+	}
+	for i, sb := range signBytes {
+		if sb.Sequence != clientState.Sequence+uint64(i) {
+			return fmt.Errorf("entry %d (%s) is signed for sequence %d, expected %d", i, file.Entries[i].Description, sb.Sequence, clientState.Sequence+uint64(i))
+		}
+		if sb.Diversifier != clientState.ConsensusState.Diversifier {
+			return fmt.Errorf("entry %d (%s) is signed with diversifier %s, but the counterparty light client uses %s", i, file.Entries[i].Description, sb.Diversifier, clientState.ConsensusState.Diversifier)
+		}
+	}
+
+	if file.Operation == OfflineOperationTransfer {
+		var packet channeltypes.Packet
+		if err := sm.cdc.Unmarshal(file.Packet, &packet); err != nil {
+			return fmt.Errorf("invalid packet: %w", err)
+		}
+		if !bytes.Equal(signBytes[1].Path, host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence)) {
+			return fmt.Errorf("entry 1 (%s) is signed for another packet than packet %d on %s/%s", file.Entries[1].Description, packet.Sequence, packet.SourcePort, packet.SourceChannel)
+		}
+		if !bytes.Equal(signBytes[1].Data, channeltypes.CommitPacket(sm.cdc, packet)) {
+			return fmt.Errorf("entry 1 (%s) is signed for another commitment than the packet in the file", file.Entries[1].Description)
+		}
+	}
+
+	return nil
+}
+
+func (sm *SoloMachine) importOfflineHeader(chainName string, signBytes *solomachineclient.SignBytes, sig []byte) error {
+	var headerData solomachineclient.HeaderData
+	if err := sm.cdc.Unmarshal(signBytes.Data, &headerData); err != nil {
+		return err
+	}
+
+	header := &solomachineclient.Header{
+		Timestamp:      signBytes.Timestamp,
+		Signature:      sig,
+		NewPublicKey:   headerData.NewPubKey,
+		NewDiversifier: headerData.NewDiversifier,
+	}
+
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if err := sm.r.UpdateClient(chainName, chainStorage.CounterpartyClientID(), header); err != nil {
+		return err
+	}
+
+	sm.logger.Info("Updated counterparty light client", zap.String("chainName", chainName))
+
+	return nil
+}
+
+func (sm *SoloMachine) addOfflineEntry(file *OfflineSigningFile, description string, signBytes *solomachineclient.SignBytes) error {
+	bz, err := sm.cdc.Marshal(signBytes)
+	if err != nil {
+		return err
+	}
+
+	file.Entries = append(file.Entries, OfflineSigningEntry{
+		Description: description,
+		SignBytes:   bz,
+	})
+
+	return nil
+}
+
+func (sm *SoloMachine) counterpartySequence(chainName string) (uint64, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	clientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return 0, err
+	}
+
+	return clientState.Sequence, nil
+}
@@ -0,0 +1,100 @@
+package solomachine
+
+import (
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const testClientSequence = 5
+
+// newTestOfflineTransfer returns a signed offline transfer file with its sign bytes, and the counterparty client it was exported for
+func newTestOfflineTransfer(t *testing.T, sm *SoloMachine) (*OfflineSigningFile, []solomachineclient.SignBytes, *solomachineclient.ClientState) {
+	t.Helper()
+	diversifier := sm.storage.GetChainStorage(testChainName).Diversifier()
+	clientState := &solomachineclient.ClientState{
+		Sequence:       testClientSequence,
+		ConsensusState: &solomachineclient.ConsensusState{Diversifier: diversifier},
+	}
+
+	packet := sentTransferPacket("stake", 40, "alice")
+	packetBz, err := sm.cdc.Marshal(&packet)
+	require.NoError(t, err)
+
+	file := &OfflineSigningFile{
+		ChainName: testChainName,
+		Operation: OfflineOperationTransfer,
+		Packet:    packetBz,
+	}
+	header := &solomachineclient.SignBytes{Sequence: testClientSequence, Diversifier: diversifier}
+	require.NoError(t, sm.addOfflineEntry(file, "counterparty light client header", header))
+	require.NoError(t, sm.addOfflineEntry(file, "packet commitment", sm.commitmentSignBytes(testChainName, packet, testClientSequence+1)))
+
+	signBytes := make([]solomachineclient.SignBytes, len(file.Entries))
+	for i, entry := range file.Entries {
+		require.NoError(t, sm.cdc.Unmarshal(entry.SignBytes, &signBytes[i]))
+	}
+
+	return file, signBytes, clientState
+}
+
+func TestCheckOfflineEntries(t *testing.T) {
+	sm := newTestSoloMachine(t)
+	file, signBytes, clientState := newTestOfflineTransfer(t, sm)
+
+	require.NoError(t, sm.checkOfflineEntries(file, signBytes, clientState))
+}
+
+func TestCheckOfflineEntriesRejectsTamperedPacket(t *testing.T) {
+	sm := newTestSoloMachine(t)
+
+	// Another amount (or receiver) in the packet changes the commitment, but not the path
+	file, signBytes, clientState := newTestOfflineTransfer(t, sm)
+	tampered := sentTransferPacket("stake", 4000, "alice")
+	packetBz, err := sm.cdc.Marshal(&tampered)
+	require.NoError(t, err)
+	file.Packet = packetBz
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "another commitment")
+
+	// Another sequence changes the path
+	file, signBytes, clientState = newTestOfflineTransfer(t, sm)
+	tampered = sentTransferPacket("stake", 40, "alice")
+	tampered.Sequence = 2
+	packetBz, err = sm.cdc.Marshal(&tampered)
+	require.NoError(t, err)
+	file.Packet = packetBz
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "another packet")
+
+	// Another source channel changes the path too
+	file, signBytes, clientState = newTestOfflineTransfer(t, sm)
+	tampered = sentTransferPacket("stake", 40, "alice")
+	tampered.SourcePort, tampered.SourceChannel = transfertypes.PortID, "channel-9"
+	packetBz, err = sm.cdc.Marshal(&tampered)
+	require.NoError(t, err)
+	file.Packet = packetBz
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "another packet")
+}
+
+func TestCheckOfflineEntriesRejectsSequenceAndDiversifier(t *testing.T) {
+	sm := newTestSoloMachine(t)
+
+	// The counterparty client has moved on since the export
+	file, signBytes, clientState := newTestOfflineTransfer(t, sm)
+	clientState.Sequence++
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "sequence has changed")
+
+	// The commitment is not signed for the sequence after the header
+	file, signBytes, clientState = newTestOfflineTransfer(t, sm)
+	signBytes[1].Sequence = testClientSequence + 2
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "signed for sequence")
+
+	// The client verifies with another diversifier
+	file, signBytes, clientState = newTestOfflineTransfer(t, sm)
+	clientState.ConsensusState.Diversifier = "other"
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "diversifier")
+
+	file, signBytes, clientState = newTestOfflineTransfer(t, sm)
+	signBytes[1].Diversifier = "other"
+	require.ErrorContains(t, sm.checkOfflineEntries(file, signBytes, clientState), "diversifier")
+}
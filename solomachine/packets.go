@@ -0,0 +1,101 @@
+package solomachine
+
+import (
+	"fmt"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+const (
+	PacketStatePending      = "pending"
+	PacketStateAcknowledged = "acknowledged"
+	PacketStateTimedOut     = "timed-out"
+)
+
+// PacketInfo is what the solo machine knows about a packet it has sent, and whether the chain has received it
+type PacketInfo struct {
+	Packet channeltypes.Packet
+	State  string
+	// Commitment is only set while the packet is pending
+	Commitment   []byte
+	ChainReceipt bool
+	// RawAcknowledgement is the acknowledgement the chain wrote, if the packet has been acknowledged
+	RawAcknowledgement []byte
+	// Acknowledgement is the decoded acknowledgement, unless the application does not use ICS-04 acknowledgements
+	Acknowledgement *channeltypes.Acknowledgement
+}
+
+// SentPackets returns the packets sent on the ICS20 channel, only the pending ones unless all is set
+func (sm *SoloMachine) SentPackets(chainName string, all bool) ([]PacketInfo, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if !chainStorage.ICS20ChannelExists() {
+		return nil, fmt.Errorf("no ICS20 channel for chain %s", chainName)
+	}
+
+	packets, err := chainStorage.SentPackets(transfertypes.PortID, chainStorage.ICS20ChannelID())
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []PacketInfo
+	for _, packet := range packets {
+		if _, pending := chainStorage.PacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence); !pending && !all {
+			continue
+		}
+
+		info, err := sm.packetInfo(chainName, packet)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// SentPacket returns the packet with the sequence sent on the ICS20 channel
+func (sm *SoloMachine) SentPacket(chainName string, sequence uint64) (PacketInfo, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if !chainStorage.ICS20ChannelExists() {
+		return PacketInfo{}, fmt.Errorf("no ICS20 channel for chain %s", chainName)
+	}
+
+	packet, err := chainStorage.SentPacket(transfertypes.PortID, chainStorage.ICS20ChannelID(), sequence)
+	if err != nil {
+		return PacketInfo{}, err
+	}
+
+	return sm.packetInfo(chainName, packet)
+}
+
+func (sm *SoloMachine) packetInfo(chainName string, packet channeltypes.Packet) (PacketInfo, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	info := PacketInfo{
+		Packet: packet,
+		State:  PacketStatePending,
+	}
+
+	if commitment, ok := chainStorage.PacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence); ok {
+		info.Commitment = commitment
+	}
+	if chainStorage.PacketTimedOut(packet.SourcePort, packet.SourceChannel, packet.Sequence) {
+		info.State = PacketStateTimedOut
+	}
+	if acknowledgement, ok := chainStorage.CounterpartyAcknowledgement(packet.SourcePort, packet.SourceChannel, packet.Sequence); ok {
+		info.State = PacketStateAcknowledged
+		info.RawAcknowledgement = acknowledgement
+		var ack channeltypes.Acknowledgement
+		if err := channeltypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err == nil {
+			info.Acknowledgement = &ack
+		}
+	}
+
+	var err error
+	info.ChainReceipt, err = sm.r.QueryPacketReceipt(chainName, packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+	if err != nil {
+		return PacketInfo{}, err
+	}
+
+	return info, nil
+}
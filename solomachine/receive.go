@@ -0,0 +1,225 @@
+package solomachine
+
+import (
+	"cmp"
+	"fmt"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+// ReceivePackets receives the packets the chain has sent on the open channels and relays the acknowledgements back to the chain
+// Each packet commitment is verified against the local light client before the packet is received. On an ordered channel the packets
+// are received strictly in order, and a packet that timed out is timed out on the chain, which closes the channel. It returns the number of packets received.
+func (sm *SoloMachine) ReceivePackets(chainName string) (int, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channels, err := sm.openChannels(chainName)
+	if err != nil {
+		return 0, err
+	}
+
+	channelPackets := make([][]channeltypes.Packet, len(channels))
+	total := 0
+	for i, channel := range channels {
+		sentPackets, err := sm.r.QuerySendPackets(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+		if err != nil {
+			return 0, err
+		}
+		for _, packet := range sentPackets {
+			if packet.DestinationPort == channel.PortId && packet.DestinationChannel == channel.ChannelId {
+				channelPackets[i] = append(channelPackets[i], packet)
+			}
+		}
+		slices.SortFunc(channelPackets[i], func(a, b channeltypes.Packet) int {
+			return cmp.Compare(a.Sequence, b.Sequence)
+		})
+		total += len(channelPackets[i])
+	}
+	if total == 0 {
+		sm.logger.Info("No packets to receive", zap.String("chain", chainName))
+		return 0, nil
+	}
+
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return 0, err
+	}
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return 0, err
+	}
+	proofHeight := lightClientState.LatestHeight
+
+	counterpartyClientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return 0, err
+	}
+	sequence := counterpartyClientState.Sequence
+
+	received := 0
+	for i, channel := range channels {
+		for _, packet := range channelPackets[i] {
+			commitment, commitmentProof, err := sm.r.QueryProof(chainName, host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), proofHeight)
+			if err != nil {
+				return received, err
+			}
+			if len(commitment) == 0 {
+				// Already acknowledged (or timed out) on the chain
+				continue
+			}
+
+			acknowledgement, ok := chainStorage.PacketAcknowledgement(packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+			if !ok {
+				if channel.Ordering == channeltypes.ORDERED {
+					if nextSequenceRecv := chainStorage.NextSequenceRecv(channel.PortId, channel.ChannelId); packet.Sequence != nextSequenceRecv {
+						sm.logger.Warn("Packet is not the next packet of the ordered channel, not receiving the rest of the channel", zap.String("chain", chainName), zap.String("channel-id", channel.ChannelId), zap.Uint64("packet-sequence", packet.Sequence), zap.Uint64("next-sequence-recv", nextSequenceRecv))
+						break
+					}
+				}
+				if sm.packetTimedOut(packet, sequence) {
+					if channel.Ordering == channeltypes.ORDERED {
+						relayed, err := sm.timeoutOrderedPacket(chainName, packet, sequence)
+						if err != nil {
+							return received, err
+						}
+						if relayed {
+							sequence++
+						}
+						break
+					}
+					sm.logger.Warn("Packet has timed out, not receiving it", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+					continue
+				}
+
+				acknowledgement, err = sm.receivePacket(chainName, packet, commitmentProof, proofHeight)
+				if err != nil {
+					return received, err
+				}
+				received++
+			}
+
+			ackProof, err := sm.generateAcknowledgementProof(chainName, packet, acknowledgement, sequence)
+			if err != nil {
+				return received, err
+			}
+			if err := sm.r.SendMsgAcknowledgement(chainName, packet, acknowledgement, ackProof, proofHeight); err != nil {
+				return received, err
+			}
+			sequence++
+
+			sm.logger.Info("Acknowledgement relayed to the chain", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+		}
+	}
+
+	sm.logger.Info("Packets received", zap.String("chain", chainName), zap.Int("received", received))
+
+	return received, nil
+}
+
+// timeoutOrderedPacket relays the timeout of a packet from the chain on an ordered channel, which closes the channel on the chain,
+// and closes the solo machine end of the channel the same way. The timeout is proven with the next receive sequence of the solo machine,
+// signed at the current height of the solo machine (the sequence of its client on the chain), which must be at or past the timeout height.
+// A timeout timestamp cannot be proven, since the timestamps of the solo machine are in milliseconds and the packet timeout timestamps are
+// in nanoseconds, so the packet is left as it is. It returns true if the timeout was relayed.
+func (sm *SoloMachine) timeoutOrderedPacket(chainName string, packet channeltypes.Packet, sequence uint64) (bool, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	proofHeight := clienttypes.NewHeight(0, sequence)
+	if packet.TimeoutHeight.IsZero() || !proofHeight.GTE(packet.TimeoutHeight) {
+		sm.logger.Warn("Packet has timed out by its timeout timestamp, which the chain cannot verify for the solo machine, not receiving the rest of the ordered channel", zap.String("chain", chainName), zap.String("channel-id", packet.DestinationChannel), zap.Uint64("packet-sequence", packet.Sequence))
+		return false, nil
+	}
+
+	nextSequenceRecv := chainStorage.NextSequenceRecv(packet.DestinationPort, packet.DestinationChannel)
+	signBytes := &solomachineclient.SignBytes{
+		Sequence:    sequence,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Diversifier: chainStorage.Diversifier(),
+		Path:        host.NextSequenceRecvKey(packet.DestinationPort, packet.DestinationChannel),
+		Data:        sdk.Uint64ToBigEndian(nextSequenceRecv),
+	}
+	unreceivedProof, err := sm.GenerateProof(chainName, signBytes)
+	if err != nil {
+		return false, err
+	}
+
+	if err := sm.r.SendMsgTimeout(chainName, packet, nextSequenceRecv, unreceivedProof, proofHeight); err != nil {
+		return false, err
+	}
+	chainStorage.CloseChannel(packet.DestinationPort, packet.DestinationChannel)
+	sm.logger.Warn("Packet timed out on the chain, the ordered channel has been closed", zap.String("chain", chainName), zap.String("port-id", packet.DestinationPort), zap.String("channel-id", packet.DestinationChannel), zap.Uint64("packet-sequence", packet.Sequence))
+
+	return true, nil
+}
+
+// receivePacket verifies the packet commitment against the light client, lets the application of the port handle the packet and records the acknowledgement
+func (sm *SoloMachine) receivePacket(chainName string, packet channeltypes.Packet, commitmentProof []byte, proofHeight clienttypes.Height) ([]byte, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	merklePath, err := ibcMerklePath(host.PacketCommitmentPath(packet.SourcePort, packet.SourceChannel, packet.Sequence))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := sm.verificationContext()
+	commitment := channeltypes.CommitPacket(sm.cdc, packet)
+	if err := chainStorage.VerifyMembership(ctx, proofHeight, commitmentProof, merklePath, commitment); err != nil {
+		return nil, fmt.Errorf("failed to verify the commitment of packet %d: %w", packet.Sequence, err)
+	}
+
+	return sm.applyReceivedPacket(chainName, packet)
+}
+
+// applyReceivedPacket lets the application of the port handle a verified packet and records the acknowledgement
+// The packet is checked first, so a packet the storage would reject (e.g. out of order) is never applied by the application.
+func (sm *SoloMachine) applyReceivedPacket(chainName string, packet channeltypes.Packet) ([]byte, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if err := chainStorage.CheckReceivePacket(packet); err != nil {
+		return nil, err
+	}
+
+	acknowledgement, err := sm.onRecvPacket(chainName, packet)
+	if err != nil {
+		return nil, err
+	}
+	if err := chainStorage.ReceivePacket(packet, acknowledgement); err != nil {
+		return nil, err
+	}
+
+	return acknowledgement, nil
+}
+
+// packetTimedOut checks the packet timeout against the height (the sequence of the client on the chain) and time of the solo machine
+func (sm *SoloMachine) packetTimedOut(packet channeltypes.Packet, sequence uint64) bool {
+	selfHeight := clienttypes.NewHeight(0, sequence)
+	if !packet.TimeoutHeight.IsZero() && selfHeight.GTE(packet.TimeoutHeight) {
+		return true
+	}
+
+	return packet.TimeoutTimestamp != 0 && uint64(time.Now().UnixNano()) >= packet.TimeoutTimestamp
+}
+
+func (sm *SoloMachine) generateAcknowledgementProof(chainName string, packet channeltypes.Packet, acknowledgement []byte, sequence uint64) ([]byte, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	signBytes := &solomachineclient.SignBytes{
+		Sequence:    sequence,
+		Timestamp:   uint64(time.Now().UnixMilli()),
+		Diversifier: chainStorage.Diversifier(),
+		Path:        host.PacketAcknowledgementKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence),
+		Data:        channeltypes.CommitAcknowledgement(acknowledgement),
+	}
+
+	return sm.GenerateProof(chainName, signBytes)
+}
+
+// verificationContext creates the context the local light client verifies proofs with
+func (sm *SoloMachine) verificationContext() sdk.Context {
+	return sdk.NewContext(sm.storage.GetRootStore(), cmtproto.Header{Time: time.Now()}, false, sm.sdkLogger)
+}
@@ -0,0 +1,53 @@
+package solomachine
+
+import (
+	sdkmath "cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// setTestChannel stores the solo machine end of an open transfer channel with the ordering
+func setTestChannel(sm *SoloMachine, ordering channeltypes.Order) {
+	chainStorage := sm.storage.GetChainStorage(testChainName)
+	chainStorage.SetChannel(transfertypes.PortID, testChannelID, channeltypes.NewChannel(channeltypes.OPEN, ordering, channeltypes.NewCounterparty(transfertypes.PortID, testCounterpartyChannel), []string{"connection-0"}, transfertypes.Version))
+}
+
+func TestApplyReceivedPacketOutOfOrder(t *testing.T) {
+	sm := newTestSoloMachine(t)
+	setTestChannel(sm, channeltypes.ORDERED)
+	voucher := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(transfertypes.PortID, testChannelID, "uatom")).IBCDenom()
+
+	packet := receivedTransferPacket("uatom", 50, "alice")
+	packet.Sequence = 2
+	_, err := sm.applyReceivedPacket(testChainName, packet)
+	require.Error(t, err)
+	sm.storage.Commit()
+	require.True(t, sm.storage.Balance("alice", voucher).IsZero(), "a packet that is not received is not applied")
+
+	packet.Sequence = 1
+	_, err = sm.applyReceivedPacket(testChainName, packet)
+	require.NoError(t, err)
+	require.Equal(t, sdkmath.NewInt(50), sm.storage.Balance("alice", voucher))
+
+	_, err = sm.applyReceivedPacket(testChainName, packet)
+	require.Error(t, err)
+	sm.storage.Commit()
+	require.Equal(t, sdkmath.NewInt(50), sm.storage.Balance("alice", voucher), "a redelivered packet is not applied again")
+}
+
+func TestApplyReceivedPacketTwiceUnordered(t *testing.T) {
+	sm := newTestSoloMachine(t)
+	setTestChannel(sm, channeltypes.UNORDERED)
+	voucher := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(transfertypes.PortID, testChannelID, "uatom")).IBCDenom()
+
+	packet := receivedTransferPacket("uatom", 50, "alice")
+	_, err := sm.applyReceivedPacket(testChainName, packet)
+	require.NoError(t, err)
+
+	_, err = sm.applyReceivedPacket(testChainName, packet)
+	require.Error(t, err)
+	sm.storage.Commit()
+	require.Equal(t, sdkmath.NewInt(50), sm.storage.Balance("alice", voucher))
+}
@@ -0,0 +1,227 @@
+package solomachine
+
+import (
+	"errors"
+	"fmt"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"strings"
+)
+
+// IBCModule is the subset of the ibc-go porttypes.IBCModule callbacks an application on the solo machine implements
+// There is no sdk.Context or channel capability, since the solo machine has no keepers, and the chain name tells the application
+// which chain the channel goes to. Channels are only closed by a timeout on an ordered channel, which has no callback (like in ibc-go).
+type IBCModule interface {
+	// OnChanOpenInit is called when the solo machine starts the channel handshake, and returns the version it proposes
+	OnChanOpenInit(chainName string, order channeltypes.Order, connectionHops []string, portID string, channelID string, counterparty channeltypes.Counterparty, version string) (string, error)
+	// OnChanOpenTry is called when the solo machine answers a channel handshake started by the chain, and returns the version it agrees to
+	OnChanOpenTry(chainName string, order channeltypes.Order, connectionHops []string, portID string, channelID string, counterparty channeltypes.Counterparty, counterpartyVersion string) (string, error)
+	OnChanOpenAck(chainName string, portID string, channelID string, counterpartyChannelID string, counterpartyVersion string) error
+	OnChanOpenConfirm(chainName string, portID string, channelID string) error
+
+	// OnRecvPacket returns the acknowledgement for a packet received from the chain, which cannot be nil (there are no async acknowledgements)
+	// An error means the packet could not be handled at all, and it is retried the next time the packets are received.
+	OnRecvPacket(chainName string, packet channeltypes.Packet) (exported.Acknowledgement, error)
+	OnAcknowledgementPacket(chainName string, packet channeltypes.Packet, acknowledgement []byte) error
+	OnTimeoutPacket(chainName string, packet channeltypes.Packet) error
+}
+
+// PacketSender is implemented by the applications that validate and apply their outgoing packets before the solo machine stores them
+// The returned packet is the one that is sent, so the application can build (or change) its data. If the packet cannot be stored
+// after it was applied, OnTimeoutPacket is called to revert it, the same way as a packet that timed out.
+type PacketSender interface {
+	OnSendPacket(chainName string, packet channeltypes.Packet) (channeltypes.Packet, error)
+}
+
+// Router routes the channel and packet callbacks to the application bound to the port
+// Applications with a port per user (like the interchain accounts controller) are bound to a port prefix instead.
+type Router struct {
+	routes       map[string]IBCModule
+	prefixRoutes map[string]IBCModule
+}
+
+func NewRouter() *Router {
+	return &Router{
+		routes:       make(map[string]IBCModule),
+		prefixRoutes: make(map[string]IBCModule),
+	}
+}
+
+// AddRoute binds the application to the port. It returns the Router so AddRoute calls can be linked,
+// and panics if the port is invalid or already bound, like the ibc-go router.
+func (rtr *Router) AddRoute(portID string, module IBCModule) *Router {
+	if err := host.PortIdentifierValidator(portID); err != nil {
+		panic(fmt.Errorf("invalid port %s: %w", portID, err))
+	}
+	if _, ok := rtr.routes[portID]; ok {
+		panic(fmt.Errorf("route %s has already been registered", portID))
+	}
+
+	rtr.routes[portID] = module
+	return rtr
+}
+
+// AddPrefixRoute binds the application to all the ports starting with the prefix, ports bound with AddRoute take precedence
+// It panics if the prefix is empty or already bound.
+func (rtr *Router) AddPrefixRoute(portPrefix string, module IBCModule) *Router {
+	if strings.TrimSpace(portPrefix) == "" {
+		panic(fmt.Errorf("port prefix cannot be empty"))
+	}
+	if _, ok := rtr.prefixRoutes[portPrefix]; ok {
+		panic(fmt.Errorf("prefix route %s has already been registered", portPrefix))
+	}
+
+	rtr.prefixRoutes[portPrefix] = module
+	return rtr
+}
+
+// HasRoute returns true if an application is bound to the port
+func (rtr *Router) HasRoute(portID string) bool {
+	_, ok := rtr.GetRoute(portID)
+	return ok
+}
+
+// HasPrefixRoute returns true if an application is bound to the port prefix
+func (rtr *Router) HasPrefixRoute(portPrefix string) bool {
+	_, ok := rtr.prefixRoutes[portPrefix]
+	return ok
+}
+
+// GetRoute returns the application bound to the port, or to the longest prefix of the port
+func (rtr *Router) GetRoute(portID string) (IBCModule, bool) {
+	if module, ok := rtr.routes[portID]; ok {
+		return module, true
+	}
+
+	var module IBCModule
+	longestPrefix := ""
+	for portPrefix, prefixModule := range rtr.prefixRoutes {
+		if strings.HasPrefix(portID, portPrefix) && len(portPrefix) > len(longestPrefix) {
+			module, longestPrefix = prefixModule, portPrefix
+		}
+	}
+
+	return module, module != nil
+}
+
+// Router returns the router of the solo machine, to bind applications to ports
+func (sm *SoloMachine) Router() *Router {
+	return sm.router
+}
+
+func (sm *SoloMachine) route(portID string) (IBCModule, error) {
+	module, ok := sm.router.GetRoute(portID)
+	if !ok {
+		return nil, fmt.Errorf("no application is bound to port %s", portID)
+	}
+
+	return module, nil
+}
+
+// sendPacket lets the application of the port apply an outgoing packet and stores its commitment, so it can be relayed (again)
+// It returns the packet as it was stored, since the application can change its data. If the packet cannot be stored, the application reverts it.
+func (sm *SoloMachine) sendPacket(chainName string, packet channeltypes.Packet) (channeltypes.Packet, error) {
+	packet, err := sm.applySendPacket(chainName, packet)
+	if err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	if err := sm.storeSentPacket(chainName, packet); err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	return packet, nil
+}
+
+// applySendPacket checks that the channel is open and lets the application of the port apply an outgoing packet, without storing it
+// A packet that is not stored afterwards has to be reverted with revertSendPackets.
+func (sm *SoloMachine) applySendPacket(chainName string, packet channeltypes.Packet) (channeltypes.Packet, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if channel, ok := chainStorage.Channel(packet.SourcePort, packet.SourceChannel); ok && channel.State != channeltypes.OPEN {
+		return channeltypes.Packet{}, fmt.Errorf("channel %s on port %s is %s", packet.SourceChannel, packet.SourcePort, channel.State)
+	}
+
+	return sm.onSendPacket(chainName, packet)
+}
+
+// storeSentPacket stores the commitment of a packet applied with applySendPacket, and reverts the packet if it cannot be stored
+func (sm *SoloMachine) storeSentPacket(chainName string, packet channeltypes.Packet) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if err := chainStorage.SendPacket(packet, channeltypes.CommitPacket(sm.cdc, packet)); err != nil {
+		return sm.revertSendPackets(chainName, []channeltypes.Packet{packet}, err)
+	}
+
+	return nil
+}
+
+// revertSendPackets lets the applications of PacketSender ports revert the packets they applied, when the packets could not be stored
+// after all, so e.g. the tokens the transfer application took from the sender are not lost with the next commit. It returns the storage error.
+func (sm *SoloMachine) revertSendPackets(chainName string, packets []channeltypes.Packet, err error) error {
+	for _, packet := range packets {
+		module, routeErr := sm.route(packet.SourcePort)
+		if routeErr != nil {
+			return errors.Join(err, routeErr)
+		}
+		if _, ok := module.(PacketSender); !ok {
+			continue
+		}
+
+		if revertErr := module.OnTimeoutPacket(chainName, packet); revertErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to revert packet %d: %w", packet.Sequence, revertErr))
+		}
+	}
+
+	return err
+}
+
+// onSendPacket lets the application of the source port validate and apply an outgoing packet, if it is a PacketSender
+func (sm *SoloMachine) onSendPacket(chainName string, packet channeltypes.Packet) (channeltypes.Packet, error) {
+	module, err := sm.route(packet.SourcePort)
+	if err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	packetSender, ok := module.(PacketSender)
+	if !ok {
+		return packet, nil
+	}
+
+	return packetSender.OnSendPacket(chainName, packet)
+}
+
+// onRecvPacket lets the application of the destination port handle a received packet and returns the acknowledgement bytes
+func (sm *SoloMachine) onRecvPacket(chainName string, packet channeltypes.Packet) ([]byte, error) {
+	module, err := sm.route(packet.DestinationPort)
+	if err != nil {
+		return nil, err
+	}
+
+	ack, err := module.OnRecvPacket(chainName, packet)
+	if err != nil {
+		return nil, err
+	}
+	if ack == nil {
+		return nil, fmt.Errorf("the application on port %s did not write an acknowledgement for packet %d", packet.DestinationPort, packet.Sequence)
+	}
+
+	return ack.Acknowledgement(), nil
+}
+
+func (sm *SoloMachine) onAcknowledgementPacket(chainName string, packet channeltypes.Packet, acknowledgement []byte) error {
+	module, err := sm.route(packet.SourcePort)
+	if err != nil {
+		return err
+	}
+
+	return module.OnAcknowledgementPacket(chainName, packet, acknowledgement)
+}
+
+func (sm *SoloMachine) onTimeoutPacket(chainName string, packet channeltypes.Packet) error {
+	module, err := sm.route(packet.SourcePort)
+	if err != nil {
+		return err
+	}
+
+	return module.OnTimeoutPacket(chainName, packet)
+}
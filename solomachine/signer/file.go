@@ -0,0 +1,32 @@
+package signer
+
+import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"os"
+)
+
+var _ Signer = &FileSigner{}
+
+// FileSigner signs with a private key read from a key file outside the solo machine database
+// The key file contains the proto JSON encoding of the private key, i.e. {"@type": "/cosmos.crypto.secp256k1.PrivKey", "key": "..."}
+type FileSigner struct {
+	*LocalSigner
+}
+
+func NewFileSigner(cdc codec.Codec, path string) (*FileSigner, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signer key file: %w", err)
+	}
+
+	var privKey cryptotypes.PrivKey
+	if err := cdc.UnmarshalInterfaceJSON(bz, &privKey); err != nil {
+		return nil, fmt.Errorf("failed to parse signer key file %s: %w", path, err)
+	}
+
+	return &FileSigner{
+		LocalSigner: NewLocalSigner(privKey),
+	}, nil
+}
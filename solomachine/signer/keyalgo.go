@@ -0,0 +1,98 @@
+package signer
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/ed25519"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256r1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/go-bip39"
+	"google.golang.org/protobuf/encoding/protowire"
+	"math/big"
+	"strings"
+)
+
+// Supported key algorithms for keys generated by the solo machine (the names match the key types)
+const (
+	KeyAlgoSecp256k1 = "secp256k1"
+	KeyAlgoEd25519   = "ed25519"
+	KeyAlgoSecp256r1 = "secp256r1"
+
+	DefaultKeyAlgo = KeyAlgoSecp256k1
+
+	// mnemonicEntropySize gives a 24 word mnemonic
+	mnemonicEntropySize = 256
+)
+
+var SupportedKeyAlgos = []string{KeyAlgoSecp256k1, KeyAlgoEd25519, KeyAlgoSecp256r1}
+
+// mnemonicHDPath is the standard cosmos derivation path, so a secp256k1 solo machine key matches the first account of other cosmos wallets
+var mnemonicHDPath = hd.CreateHDPath(118, 0, 0).String()
+
+// GenerateKey generates a new BIP39 mnemonic and derives a private key with the given key algorithm from it
+func GenerateKey(keyAlgo string) (cryptotypes.PrivKey, string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropySize)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	privKey, err := KeyFromMnemonic(keyAlgo, mnemonic)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return privKey, mnemonic, nil
+}
+
+// KeyFromMnemonic derives the private key with the given key algorithm from a BIP39 mnemonic
+// All algorithms use the secret derived with the standard cosmos HD path, so the same mnemonic and algorithm always give the same key.
+func KeyFromMnemonic(keyAlgo string, mnemonic string) (cryptotypes.PrivKey, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid mnemonic")
+	}
+
+	secret, err := hd.Secp256k1.Derive()(mnemonic, "", mnemonicHDPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyAlgo {
+	case KeyAlgoSecp256k1:
+		return hd.Secp256k1.Generate()(secret), nil
+	case KeyAlgoEd25519:
+		return ed25519.GenPrivKeyFromSecret(secret), nil
+	case KeyAlgoSecp256r1:
+		return secp256r1PrivKeyFromSecret(secret)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %s, supported algorithms are %v", keyAlgo, SupportedKeyAlgos)
+	}
+}
+
+// secp256r1PrivKeyFromSecret uses the 32 byte secret as the secp256r1 scalar
+func secp256r1PrivKeyFromSecret(secret []byte) (cryptotypes.PrivKey, error) {
+	d := new(big.Int).SetBytes(secret)
+	if d.Sign() == 0 || d.Cmp(elliptic.P256().Params().N) >= 0 {
+		return nil, fmt.Errorf("the secret derived from the mnemonic is not a valid secp256r1 key")
+	}
+
+	privKey := &secp256r1.PrivKey{}
+	if err := privKey.Unmarshal(secp256r1KeyProto(secret)); err != nil {
+		return nil, fmt.Errorf("cannot derive a secp256r1 key from the mnemonic: %w", err)
+	}
+
+	return privKey, nil
+}
+
+// secp256r1KeyProto encodes the key bytes as the protobuf of a secp256r1 PrivKey or PubKey (field 1, bytes)
+// The SDK keeps the key field types unexported, so the keys can only be built from their protobuf encoding.
+func secp256r1KeyProto(key []byte) []byte {
+	bz := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(bz, key)
+}
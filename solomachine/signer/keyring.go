@@ -0,0 +1,49 @@
+package signer
+
+import (
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+var _ Signer = &KeyringSigner{}
+
+// KeyringSigner signs with a named key in a cosmos sdk keyring
+type KeyringSigner struct {
+	kr      keyring.Keyring
+	keyName string
+	pubKey  cryptotypes.PubKey
+}
+
+func NewKeyringSigner(kr keyring.Keyring, keyName string) (*KeyringSigner, error) {
+	record, err := kr.Key(keyName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer key %s from keyring: %w", keyName, err)
+	}
+
+	pubKey, err := record.GetPubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyringSigner{
+		kr:      kr,
+		keyName: keyName,
+		pubKey:  pubKey,
+	}, nil
+}
+
+func (s *KeyringSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s *KeyringSigner) Sign(msg []byte) ([]byte, error) {
+	// The sign mode is only used by ledger devices, the solo machine signs raw sign bytes
+	sig, _, err := s.kr.Sign(s.keyName, msg, signing.SignMode_SIGN_MODE_DIRECT)
+	return sig, err
+}
+
+func (s *KeyringSigner) KeyType() string {
+	return s.pubKey.Type()
+}
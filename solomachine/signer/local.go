@@ -0,0 +1,28 @@
+package signer
+
+import cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+
+var _ Signer = &LocalSigner{}
+
+// LocalSigner signs with an in-memory private key
+type LocalSigner struct {
+	privKey cryptotypes.PrivKey
+}
+
+func NewLocalSigner(privKey cryptotypes.PrivKey) *LocalSigner {
+	return &LocalSigner{
+		privKey: privKey,
+	}
+}
+
+func (s *LocalSigner) PubKey() cryptotypes.PubKey {
+	return s.privKey.PubKey()
+}
+
+func (s *LocalSigner) Sign(msg []byte) ([]byte, error) {
+	return s.privKey.Sign(msg)
+}
+
+func (s *LocalSigner) KeyType() string {
+	return s.privKey.Type()
+}
@@ -0,0 +1,92 @@
+package signer
+
+import (
+	"fmt"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"go.uber.org/zap"
+)
+
+var _ MultiSignatureSigner = &MultiSigner{}
+
+// MultiSignatureSigner is implemented by signers that produce multi signatures instead of a single raw signature
+type MultiSignatureSigner interface {
+	Signer
+	SignMulti(msg []byte) (*signing.MultiSignatureData, error)
+}
+
+// MultiSigner signs with a threshold (M-of-N) multisig key made up of the public keys of the member signers
+// The order of the members determines the multisig public key, so it must not change after the clients are created
+type MultiSigner struct {
+	logger    *zap.Logger
+	pubKey    *kmultisig.LegacyAminoPubKey
+	members   []Signer
+	threshold int
+}
+
+func NewMultiSigner(logger *zap.Logger, threshold int, members []Signer) (*MultiSigner, error) {
+	if threshold <= 0 || threshold > len(members) {
+		return nil, fmt.Errorf("invalid multisig threshold %d for %d members", threshold, len(members))
+	}
+
+	pubKeys := make([]cryptotypes.PubKey, len(members))
+	for i, member := range members {
+		pubKeys[i] = member.PubKey()
+		for j := 0; j < i; j++ {
+			if pubKeys[j].Equals(pubKeys[i]) {
+				return nil, fmt.Errorf("multisig member %d has the same public key as member %d", i, j)
+			}
+		}
+	}
+
+	return &MultiSigner{
+		logger:    logger,
+		pubKey:    kmultisig.NewLegacyAminoPubKey(threshold, pubKeys),
+		members:   members,
+		threshold: threshold,
+	}, nil
+}
+
+func (s *MultiSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+// Sign is not supported for multisig keys, the signatures from the members are combined with SignMulti instead
+func (s *MultiSigner) Sign(_ []byte) ([]byte, error) {
+	return nil, fmt.Errorf("multisig signer does not produce single signatures, use SignMulti")
+}
+
+func (s *MultiSigner) KeyType() string {
+	return s.pubKey.Type()
+}
+
+// SignMulti collects signatures from the members until the threshold is reached
+// Members that fail to sign are skipped, so that the signature can still be produced as long as enough members are available
+func (s *MultiSigner) SignMulti(msg []byte) (*signing.MultiSignatureData, error) {
+	pubKeys := s.pubKey.GetPubKeys()
+	multiSig := multisig.NewMultisig(len(pubKeys))
+
+	signatures := 0
+	for i, member := range s.members {
+		if signatures == s.threshold {
+			break
+		}
+
+		sig, err := member.Sign(msg)
+		if err != nil {
+			s.logger.Warn("multisig member failed to sign", zap.Int("member", i), zap.Error(err))
+			continue
+		}
+
+		multisig.AddSignature(multiSig, &signing.SingleSignatureData{Signature: sig}, i)
+		signatures++
+	}
+
+	if signatures < s.threshold {
+		return nil, fmt.Errorf("not enough multisig signatures: got %d, need %d", signatures, s.threshold)
+	}
+
+	return multiSig, nil
+}
@@ -0,0 +1,43 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// ErrOfflineSigner is returned when something needs to be signed inline while the signing key is offline
+var ErrOfflineSigner = errors.New("the solo machine signer is offline, use the offline export, sign and import commands instead")
+
+var _ Signer = &OfflineSigner{}
+
+// OfflineSigner only knows the public key of a signing key that lives on another (air-gapped) machine
+// It cannot sign, everything that needs a signature has to go through the offline signing workflow.
+type OfflineSigner struct {
+	pubKey cryptotypes.PubKey
+}
+
+// NewOfflineSigner creates an offline signer from the proto JSON encoding of the public key (as printed by the offline pubkey command)
+func NewOfflineSigner(cdc codec.Codec, pubKeyJSON string) (*OfflineSigner, error) {
+	var pubKey cryptotypes.PubKey
+	if err := cdc.UnmarshalInterfaceJSON([]byte(pubKeyJSON), &pubKey); err != nil {
+		return nil, fmt.Errorf("failed to parse offline signer public key: %w", err)
+	}
+
+	return &OfflineSigner{
+		pubKey: pubKey,
+	}, nil
+}
+
+func (s *OfflineSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s *OfflineSigner) Sign(_ []byte) ([]byte, error) {
+	return nil, ErrOfflineSigner
+}
+
+func (s *OfflineSigner) KeyType() string {
+	return s.pubKey.Type()
+}
@@ -0,0 +1,52 @@
+package signer
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/client/input"
+	"os"
+	"strings"
+)
+
+// PassphraseEnvVar is the environment variable the passphrase for the local signer key is read from
+const PassphraseEnvVar = "SOLO_MACHINE_PASSPHRASE"
+
+// ReadPassphrase reads the passphrase that encrypts the local signer key
+// It is read from the environment variable, the passphrase file (if not empty) or a prompt, in that order.
+// If confirm is true, a prompted passphrase has to be entered twice (used when a new key is created).
+func ReadPassphrase(passphraseFile string, confirm bool) (string, error) {
+	if passphrase, ok := os.LookupEnv(PassphraseEnvVar); ok {
+		return passphrase, nil
+	}
+
+	if passphraseFile != "" {
+		bz, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(bz), "\r\n"), nil
+	}
+
+	return PromptPassphrase("Enter passphrase for the solo machine key:", confirm)
+}
+
+// PromptPassphrase prompts for a passphrase, twice if confirm is true
+func PromptPassphrase(prompt string, confirm bool) (string, error) {
+	buf := bufio.NewReader(os.Stdin)
+	passphrase, err := input.GetPassword(prompt, buf)
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		repeated, err := input.GetPassword("Repeat passphrase:", buf)
+		if err != nil {
+			return "", err
+		}
+		if passphrase != repeated {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
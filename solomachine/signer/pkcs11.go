@@ -0,0 +1,111 @@
+package signer
+
+import (
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256r1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	dcrsecp256k1 "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// PKCS11PinEnvVar is the environment variable the PIN of the PKCS#11 token is read from
+const PKCS11PinEnvVar = "SOLO_MACHINE_PKCS11_PIN"
+
+var (
+	oidNamedCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// ReadPKCS11Pin reads the user PIN of the PKCS#11 token
+// It is read from the environment variable, the PIN file (if not empty) or a prompt, in that order.
+func ReadPKCS11Pin(pinFile string) (string, error) {
+	if pin, ok := os.LookupEnv(PKCS11PinEnvVar); ok {
+		return pin, nil
+	}
+
+	if pinFile != "" {
+		bz, err := os.ReadFile(pinFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read PIN file: %w", err)
+		}
+		return strings.TrimRight(string(bz), "\r\n"), nil
+	}
+
+	return PromptPassphrase("Enter the PIN of the PKCS#11 token:", false)
+}
+
+// ecPubKeyFromPKCS11 creates the public key from the CKA_EC_PARAMS and CKA_EC_POINT attributes of an EC key object
+// Only secp256k1 and secp256r1 (P-256) keys are supported, since those are the EC keys the solo machine client can verify.
+func ecPubKeyFromPKCS11(ecParams []byte, ecPoint []byte) (cryptotypes.PubKey, error) {
+	var curveOID asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(ecParams, &curveOID); err != nil {
+		return nil, fmt.Errorf("unsupported EC parameters (only named curves are supported): %w", err)
+	}
+
+	// The point should be DER encoded as an octet string, but some tokens return the raw (uncompressed or compressed) point
+	point := ecPoint
+	isRawPoint := (len(ecPoint) == 65 && ecPoint[0] == 0x04) || (len(ecPoint) == 33 && (ecPoint[0] == 0x02 || ecPoint[0] == 0x03))
+	if !isRawPoint {
+		if _, err := asn1.Unmarshal(ecPoint, &point); err != nil {
+			return nil, fmt.Errorf("invalid EC point: %w", err)
+		}
+	}
+
+	switch {
+	case curveOID.Equal(oidNamedCurveSecp256k1):
+		pubKey, err := dcrsecp256k1.ParsePubKey(point)
+		if err != nil {
+			return nil, err
+		}
+		return &secp256k1.PubKey{Key: pubKey.SerializeCompressed()}, nil
+	case curveOID.Equal(oidNamedCurveP256):
+		x, y := elliptic.Unmarshal(elliptic.P256(), point)
+		if x == nil {
+			x, y = elliptic.UnmarshalCompressed(elliptic.P256(), point)
+		}
+		if x == nil {
+			return nil, fmt.Errorf("invalid P-256 public key point")
+		}
+		compressed := elliptic.MarshalCompressed(elliptic.P256(), x, y)
+		pubKey := &secp256r1.PubKey{}
+		if err := pubKey.Unmarshal(secp256r1KeyProto(compressed)); err != nil {
+			return nil, err
+		}
+		return pubKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported curve %s, only secp256k1 and secp256r1 keys are supported", curveOID)
+	}
+}
+
+// normalizeECDSASignature turns a raw r || s PKCS#11 ECDSA signature into the low-s r || s form the cosmos public keys verify
+func normalizeECDSASignature(sig []byte, curveOrder *big.Int) ([]byte, error) {
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("unexpected ECDSA signature length %d", len(sig))
+	}
+
+	s := new(big.Int).SetBytes(sig[32:])
+	halfOrder := new(big.Int).Rsh(curveOrder, 1)
+	if s.Cmp(halfOrder) > 0 {
+		s.Sub(curveOrder, s)
+	}
+
+	normalized := make([]byte, 64)
+	copy(normalized[:32], sig[:32])
+	s.FillBytes(normalized[32:])
+
+	return normalized, nil
+}
+
+// curveOrder returns the order of the curve of the public key (only secp256k1 and secp256r1)
+func curveOrder(pubKey cryptotypes.PubKey) *big.Int {
+	if _, ok := pubKey.(*secp256k1.PubKey); ok {
+		return dcrsecp256k1.S256().N
+	}
+
+	return elliptic.P256().Params().N
+}
@@ -0,0 +1,10 @@
+//go:build !pkcs11
+
+package signer
+
+import "fmt"
+
+// NewPKCS11Signer is not available without the pkcs11 build tag, since the PKCS#11 signer needs cgo
+func NewPKCS11Signer(modulePath string, slot uint, pin string, keyLabel string) (Signer, error) {
+	return nil, fmt.Errorf("the solo machine is built without PKCS#11 support, build it with the pkcs11 build tag (make install-pkcs11)")
+}
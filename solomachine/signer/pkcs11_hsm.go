@@ -0,0 +1,146 @@
+//go:build pkcs11
+
+package signer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/miekg/pkcs11"
+	"sync"
+)
+
+var _ Signer = &PKCS11Signer{}
+
+// PKCS11Signer signs with an EC key that never leaves the PKCS#11 token (e.g. an HSM or SoftHSM)
+type PKCS11Signer struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	privKey pkcs11.ObjectHandle
+	pubKey  cryptotypes.PubKey
+}
+
+// NewPKCS11Signer loads the PKCS#11 module, logs in to the token in the slot and finds the key pair with the label
+func NewPKCS11Signer(modulePath string, slot uint, pin string, keyLabel string) (_ Signer, err error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("failed to load PKCS#11 module %s", modulePath)
+	}
+
+	// On error, only what this call set up is torn down, a module or login that was already there is left alone
+	var session pkcs11.SessionHandle
+	var initialized, sessionOpened, loggedIn bool
+	defer func() {
+		if err == nil {
+			return
+		}
+		if loggedIn {
+			_ = ctx.Logout(session)
+		}
+		if sessionOpened {
+			_ = ctx.CloseSession(session)
+		}
+		if initialized {
+			_ = ctx.Finalize()
+		}
+		ctx.Destroy()
+	}()
+
+	if err := ctx.Initialize(); err == nil {
+		initialized = true
+	} else if err != pkcs11.Error(pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, fmt.Errorf("failed to initialize PKCS#11 module %s: %w", modulePath, err)
+	}
+
+	session, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a session with slot %d: %w", slot, err)
+	}
+	sessionOpened = true
+	if err := ctx.Login(session, pkcs11.CKU_USER, pin); err == nil {
+		loggedIn = true
+	} else if err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		return nil, fmt.Errorf("failed to log in to the token in slot %d: %w", slot, err)
+	}
+
+	privKey, err := findPKCS11Object(ctx, session, pkcs11.CKO_PRIVATE_KEY, keyLabel)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyObject, err := findPKCS11Object(ctx, session, pkcs11.CKO_PUBLIC_KEY, keyLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := ctx.GetAttributeValue(session, pubKeyObject, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the public key %s: %w", keyLabel, err)
+	}
+	pubKey, err := ecPubKeyFromPKCS11(attributes[0].Value, attributes[1].Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PKCS11Signer{
+		ctx:     ctx,
+		session: session,
+		privKey: privKey,
+		pubKey:  pubKey,
+	}, nil
+}
+
+func (s *PKCS11Signer) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+// Sign hashes the message and signs the hash with CKM_ECDSA inside the token, the same way the cosmos ECDSA keys sign
+func (s *PKCS11Signer) Sign(msg []byte) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	digest := sha256.Sum256(msg)
+	if err := s.ctx.SignInit(s.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, s.privKey); err != nil {
+		return nil, err
+	}
+	sig, err := s.ctx.Sign(s.session, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return normalizeECDSASignature(sig, curveOrder(s.pubKey))
+}
+
+func (s *PKCS11Signer) KeyType() string {
+	return s.pubKey.Type()
+}
+
+// findPKCS11Object finds the single object of the class with the label
+func findPKCS11Object(ctx *pkcs11.Ctx, session pkcs11.SessionHandle, class uint, label string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := ctx.FindObjectsInit(session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := ctx.FindObjects(session, 2)
+	if err != nil {
+		return 0, err
+	}
+	if err := ctx.FindObjectsFinal(session); err != nil {
+		return 0, err
+	}
+
+	switch len(objects) {
+	case 0:
+		return 0, fmt.Errorf("no key with label %s found on the token", label)
+	case 1:
+		return objects[0], nil
+	default:
+		return 0, fmt.Errorf("more than one key with label %s found on the token", label)
+	}
+}
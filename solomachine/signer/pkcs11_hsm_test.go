@@ -0,0 +1,40 @@
+//go:build pkcs11
+
+package signer
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+// TestPKCS11Signer signs with a key pair in a SoftHSM (or any other PKCS#11) token, see the README for setting one up.
+// It only runs when SOLO_MACHINE_PKCS11_TEST_MODULE is set: go test -tags pkcs11 ./solomachine/signer/...
+func TestPKCS11Signer(t *testing.T) {
+	modulePath := os.Getenv("SOLO_MACHINE_PKCS11_TEST_MODULE")
+	if modulePath == "" {
+		t.Skip("SOLO_MACHINE_PKCS11_TEST_MODULE is not set")
+	}
+	slot, err := strconv.ParseUint(os.Getenv("SOLO_MACHINE_PKCS11_TEST_SLOT"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid SOLO_MACHINE_PKCS11_TEST_SLOT: %v", err)
+	}
+	keyLabel := os.Getenv("SOLO_MACHINE_PKCS11_TEST_KEY_LABEL")
+	pin := os.Getenv(PKCS11PinEnvVar)
+
+	signer, err := NewPKCS11Signer(modulePath, uint(slot), pin, keyLabel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("solo machine sign bytes")
+	for i := 0; i < 10; i++ {
+		sig, err := signer.Sign(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !signer.PubKey().VerifySignature(msg, sig) {
+			t.Fatalf("signature %d from the token does not verify with the %s public key", i, signer.KeyType())
+		}
+	}
+}
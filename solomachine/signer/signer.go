@@ -0,0 +1,124 @@
+package signer
+
+import (
+	"fmt"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// TypeLocal signs with a key stored in the solo machine database (the default)
+	TypeLocal = "local"
+	// TypeKeyring signs with a key from a cosmos sdk keyring
+	TypeKeyring = "keyring"
+	// TypeFile signs with a key read from a separate key file
+	TypeFile = "file"
+	// TypeMultisig signs with a threshold multisig key made up of other signers
+	TypeMultisig = "multisig"
+	// TypeOffline only holds the public key, signatures are produced on another machine
+	TypeOffline = "offline"
+	// TypePKCS11 signs with a key kept in a PKCS#11 token, like an HSM (needs the pkcs11 build tag)
+	TypePKCS11 = "pkcs11"
+)
+
+// Signer is the identity the solo machine uses to sign headers and proofs for the counterparty solo machine clients
+type Signer interface {
+	// PubKey returns the public key that is registered in the counterparty solo machine clients
+	PubKey() cryptotypes.PubKey
+	// Sign signs the provided (solo machine sign) bytes and returns the raw signature
+	Sign(msg []byte) ([]byte, error)
+	// KeyType returns the key algorithm of the signer (e.g. secp256k1)
+	KeyType() string
+}
+
+// Config selects and configures the signer used by the solo machine
+type Config struct {
+	Type           string   `yaml:"type"`                      // one of local, keyring, file, multisig, offline or pkcs11 (defaults to local)
+	PassphraseFile string   `yaml:"passphrase-file,omitempty"` // local only, file with the passphrase that encrypts the key (relative paths are resolved against the home directory)
+	KeyAlgorithm   string   `yaml:"key-algorithm,omitempty"`   // local only, the algorithm used when the key is created (secp256k1, ed25519 or secp256r1)
+	KeyringBackend string   `yaml:"keyring-backend,omitempty"` // keyring only
+	KeyName        string   `yaml:"key-name,omitempty"`        // keyring only
+	KeyFile        string   `yaml:"key-file,omitempty"`        // file only, relative paths are resolved against the home directory
+	PublicKey      string   `yaml:"public-key,omitempty"`      // offline only, the proto JSON encoded public key
+	Threshold      int      `yaml:"threshold,omitempty"`       // multisig only
+	Members        []Config `yaml:"members,omitempty"`         // multisig only, the order of the members must never change
+	Module         string   `yaml:"module,omitempty"`          // pkcs11 only, path of the PKCS#11 module library (e.g. libsofthsm2.so)
+	Slot           uint     `yaml:"slot,omitempty"`            // pkcs11 only, the slot of the token
+	PinFile        string   `yaml:"pin-file,omitempty"`        // pkcs11 only, file with the user PIN (relative paths are resolved against the home directory)
+	KeyLabel       string   `yaml:"key-label,omitempty"`       // pkcs11 only, label of the EC key pair (secp256k1 or secp256r1) on the token
+}
+
+func (c Config) Validate() error {
+	switch c.Type {
+	case "", TypeLocal:
+		if c.KeyAlgorithm != "" && !slices.Contains(SupportedKeyAlgos, c.KeyAlgorithm) {
+			return fmt.Errorf("unsupported key-algorithm %s, supported algorithms are %v", c.KeyAlgorithm, SupportedKeyAlgos)
+		}
+		return nil
+	case TypeKeyring:
+		if c.KeyringBackend == "" {
+			return fmt.Errorf("keyring-backend is required for the keyring signer")
+		}
+		if c.KeyName == "" {
+			return fmt.Errorf("key-name is required for the keyring signer")
+		}
+		return nil
+	case TypeFile:
+		if c.KeyFile == "" {
+			return fmt.Errorf("key-file is required for the file signer")
+		}
+		return nil
+	case TypeOffline:
+		if c.PublicKey == "" {
+			return fmt.Errorf("public-key is required for the offline signer")
+		}
+		return nil
+	case TypePKCS11:
+		if c.Module == "" {
+			return fmt.Errorf("module is required for the pkcs11 signer")
+		}
+		if c.KeyLabel == "" {
+			return fmt.Errorf("key-label is required for the pkcs11 signer")
+		}
+		return nil
+	case TypeMultisig:
+		if c.Threshold <= 0 || c.Threshold > len(c.Members) {
+			return fmt.Errorf("multisig threshold must be between 1 and the number of members (%d), got %d", len(c.Members), c.Threshold)
+		}
+		seen := make(map[string]int, len(c.Members))
+		for i, member := range c.Members {
+			if member.Type == TypeMultisig || member.Type == TypeOffline {
+				return fmt.Errorf("multisig member %d: %s signers cannot be multisig members", i, member.Type)
+			}
+			if err := member.Validate(); err != nil {
+				return fmt.Errorf("multisig member %d: %w", i, err)
+			}
+			// A key that is a member twice would count twice towards the threshold
+			if j, ok := seen[member.keyIdentity()]; ok {
+				return fmt.Errorf("multisig member %d uses the same key as member %d", i, j)
+			}
+			seen[member.keyIdentity()] = i
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown signer type: %s", c.Type)
+	}
+}
+
+// keyIdentity identifies the key a (non multisig) signer configuration signs with
+// There is only one local key, so all local configurations are the same key.
+func (c Config) keyIdentity() string {
+	switch c.Type {
+	case "", TypeLocal:
+		return TypeLocal
+	case TypeKeyring:
+		return fmt.Sprintf("%s/%s/%s", TypeKeyring, c.KeyringBackend, c.KeyName)
+	case TypeFile:
+		return fmt.Sprintf("%s/%s", TypeFile, filepath.Clean(c.KeyFile))
+	case TypePKCS11:
+		return fmt.Sprintf("%s/%s/%d/%s", TypePKCS11, filepath.Clean(c.Module), c.Slot, c.KeyLabel)
+	default:
+		return c.Type
+	}
+}
@@ -1,13 +1,19 @@
 package solomachine
 
 import (
+	"fmt"
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/cosmos-sdk/codec"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	icatypes "github.com/cosmos/ibc-go/v8/modules/apps/27-interchain-accounts/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
 	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
 	solomachineclient "github.com/cosmos/ibc-go/v8/modules/light-clients/06-solomachine"
 	"github.com/gjermundgaraba/solo-machine/relayer"
+	"github.com/gjermundgaraba/solo-machine/solomachine/signer"
+	"github.com/gjermundgaraba/solo-machine/solomachine/statemachine"
 	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
 	"github.com/gjermundgaraba/solo-machine/utils"
 	"go.uber.org/zap"
@@ -20,12 +26,73 @@ type SoloMachine struct {
 	sdkLogger utils.SDKLoggerWrapper
 	cdc       codec.Codec
 
-	r *relayer.Relayer
+	r       *relayer.Relayer
+	homedir string
 
 	storage *smstorage.Storage
+	signer  signer.Signer
+	// Routes the channels and packets to the applications bound to their ports
+	router *Router
+	// Signers for the chains that have their own key, instead of the shared signer
+	chainSigners map[string]signer.Signer
+
+	// Set when the local signer key has been unlocked (also when it is a multisig member)
+	localKeyUnlocked bool
+	// The passphrase of the local keys, once it has been read from passphraseFile (or the environment or a prompt)
+	localKeyPassphrase string
+	passphraseRead     bool
+	passphraseFile     string
 }
 
-func NewSoloMachine(logger *zap.Logger, cdc codec.Codec, r *relayer.Relayer, homedir string) *SoloMachine {
+func NewSoloMachine(logger *zap.Logger, cdc codec.Codec, r *relayer.Relayer, homedir string, signerConfig signer.Config, stateMachineConfig statemachine.Config) (*SoloMachine, error) {
+	return NewSoloMachineWithRouter(logger, cdc, r, homedir, signerConfig, stateMachineConfig, NewRouter())
+}
+
+// NewSoloMachineWithRouter creates a solo machine with the applications already bound in the router (e.g. an ICS20 application of its own)
+// The built-in applications are only bound to the ports the router (and the external state machine) leaves free.
+func NewSoloMachineWithRouter(logger *zap.Logger, cdc codec.Codec, r *relayer.Relayer, homedir string, signerConfig signer.Config, stateMachineConfig statemachine.Config, router *Router) (*SoloMachine, error) {
+	sm := newSoloMachine(logger, cdc, r, homedir)
+	sm.router = router
+	if localConfig, ok := localSignerConfig(signerConfig); ok && localConfig.PassphraseFile != "" {
+		sm.passphraseFile = sm.homePath(localConfig.PassphraseFile)
+	}
+
+	var err error
+	sm.signer, err = sm.newSigner(signerConfig)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("using signer", zap.String("type", signerConfig.Type), zap.String("key-type", sm.signer.KeyType()))
+
+	// The external state machine gets its ports (the transfer port by default), the built-in transfer application gets the transfer port otherwise
+	if stateMachineConfig.Enabled() {
+		client, err := statemachine.NewClient(stateMachineConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, portID := range stateMachineConfig.BoundPorts() {
+			if err := host.PortIdentifierValidator(portID); err != nil {
+				return nil, fmt.Errorf("invalid state machine port %s: %w", portID, err)
+			}
+			if sm.router.HasRoute(portID) {
+				return nil, fmt.Errorf("state machine port %s is listed more than once or already bound", portID)
+			}
+			sm.router.AddRoute(portID, externalStateMachine{client: client})
+		}
+		logger.Debug("using external state machine", zap.String("grpc-addr", stateMachineConfig.GRPCAddr), zap.Strings("ports", stateMachineConfig.BoundPorts()))
+	}
+	if !sm.router.HasRoute(transfertypes.PortID) {
+		sm.router.AddRoute(transfertypes.PortID, transferModule{sm: sm})
+	}
+	if !sm.router.HasPrefixRoute(icatypes.ControllerPortPrefix) {
+		sm.router.AddPrefixRoute(icatypes.ControllerPortPrefix, icaControllerModule{sm: sm})
+	}
+
+	return sm, nil
+}
+
+// newSoloMachine opens the storage in the home directory, without setting up a signer
+func newSoloMachine(logger *zap.Logger, cdc codec.Codec, r *relayer.Relayer, homedir string) *SoloMachine {
 	dbName := "solo-machine"
 	db, err := dbm.NewGoLevelDB(dbName, homedir, nil)
 	if err != nil {
@@ -35,34 +102,51 @@ func NewSoloMachine(logger *zap.Logger, cdc codec.Codec, r *relayer.Relayer, hom
 
 	storage := smstorage.NewStorage(db, logger, cdc)
 
-	sm := &SoloMachine{
+	return &SoloMachine{
 		logger:    logger,
 		sdkLogger: utils.NewSDKLoggerWrapper(logger),
 		cdc:       cdc,
 
-		r: r,
+		r:       r,
+		homedir: homedir,
 
-		storage: storage,
+		storage:      storage,
+		router:       NewRouter(),
+		chainSigners: make(map[string]signer.Signer),
 	}
+}
 
-	return sm
+// PublicKey returns the public key of the solo machine signer
+func (sm *SoloMachine) PublicKey() cryptotypes.PubKey {
+	return sm.signer.PubKey()
 }
 
-// GenerateProof takes in solo machine sign bytes, generates a signature and marshals it as a proof.
-func (sm *SoloMachine) GenerateProof(signBytes *solomachineclient.SignBytes) ([]byte, error) {
+// SignsOffline returns true if the signing key is offline, which means that nothing can be signed inline
+func (sm *SoloMachine) SignsOffline() bool {
+	_, ok := sm.signer.(*signer.OfflineSigner)
+	return ok
+}
+
+// GenerateProof takes in solo machine sign bytes, generates a signature with the key of the chain and marshals it as a proof.
+func (sm *SoloMachine) GenerateProof(chainName string, signBytes *solomachineclient.SignBytes) ([]byte, error) {
 	bz, err := sm.cdc.Marshal(signBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	sig, err := sm.GenerateSignature(bz)
+	sig, err := sm.GenerateSignature(chainName, bz)
 	if err != nil {
 		return nil, err
 	}
 
+	return sm.proofFromSignature(sig, signBytes.Timestamp)
+}
+
+// proofFromSignature marshals a signature (from GenerateSignature) over sign bytes with the given timestamp as a proof
+func (sm *SoloMachine) proofFromSignature(sig []byte, timestamp uint64) ([]byte, error) {
 	signatureDoc := &solomachineclient.TimestampedSignatureData{
 		SignatureData: sig,
-		Timestamp:     signBytes.Timestamp,
+		Timestamp:     timestamp,
 	}
 	proof, err := sm.cdc.Marshal(signatureDoc)
 	if err != nil {
@@ -72,13 +156,29 @@ func (sm *SoloMachine) GenerateProof(signBytes *solomachineclient.SignBytes) ([]
 	return proof, nil
 }
 
-func (sm *SoloMachine) GenerateSignature(bz []byte) ([]byte, error) {
-	sig, err := sm.storage.Sign(bz)
+// GenerateSignature signs the bytes with the key of the chain and marshals the signature data the way the solo machine client expects it
+// For multisig public keys this is multi signature data with signatures from the members, otherwise a single signature.
+func (sm *SoloMachine) GenerateSignature(chainName string, bz []byte) ([]byte, error) {
+	chainSigner, err := sm.chainSigner(chainName)
 	if err != nil {
 		return nil, err
 	}
-	signatureData := &signing.SingleSignatureData{
-		Signature: sig,
+
+	var signatureData signing.SignatureData
+	if multiSigner, ok := chainSigner.(signer.MultiSignatureSigner); ok {
+		multiSignatureData, err := multiSigner.SignMulti(bz)
+		if err != nil {
+			return nil, err
+		}
+		signatureData = multiSignatureData
+	} else {
+		sig, err := chainSigner.Sign(bz)
+		if err != nil {
+			return nil, err
+		}
+		signatureData = &signing.SingleSignatureData{
+			Signature: sig,
+		}
 	}
 	protoSigData := signing.SignatureDataToProto(signatureData)
 	return sm.cdc.Marshal(protoSigData)
@@ -86,17 +186,19 @@ func (sm *SoloMachine) GenerateSignature(bz []byte) ([]byte, error) {
 
 // GenerateCommitmentProof generates a commitment proof for the provided packet.
 func (sm *SoloMachine) GenerateCommitmentProof(chainName string, packet channeltypes.Packet, sequence uint64) ([]byte, error) {
+	return sm.GenerateProof(chainName, sm.commitmentSignBytes(chainName, packet, sequence))
+}
+
+func (sm *SoloMachine) commitmentSignBytes(chainName string, packet channeltypes.Packet, sequence uint64) *solomachineclient.SignBytes {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 	commitment := channeltypes.CommitPacket(sm.cdc, packet)
 
 	path := host.PacketCommitmentKey(packet.GetSourcePort(), packet.GetSourceChannel(), packet.GetSequence())
-	signBytes := &solomachineclient.SignBytes{
+	return &solomachineclient.SignBytes{
 		Sequence:    sequence,
 		Timestamp:   uint64(time.Now().UnixMilli()),
 		Diversifier: chainStorage.Diversifier(),
 		Path:        path,
 		Data:        commitment,
 	}
-
-	return sm.GenerateProof(signBytes)
 }
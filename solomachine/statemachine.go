@@ -0,0 +1,121 @@
+package solomachine
+
+import (
+	"context"
+	"fmt"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"github.com/gjermundgaraba/solo-machine/solomachine/statemachine"
+	"time"
+)
+
+// stateMachineCallTimeout is how long a call to the external state machine can take
+const stateMachineCallTimeout = 30 * time.Second
+
+var (
+	_ IBCModule    = externalStateMachine{}
+	_ PacketSender = externalStateMachine{}
+)
+
+// externalStateMachine is the application that hands the packets to the external state machine over gRPC
+// The StateMachine service has no channel callbacks, so it accepts the channel handshakes on its port as they are.
+// The packet callbacks are at least once, the service has to be idempotent on the packet (see the StateMachine proto).
+type externalStateMachine struct {
+	client statemachine.StateMachineClient
+}
+
+func (externalStateMachine) OnChanOpenInit(_ string, _ channeltypes.Order, _ []string, _ string, _ string, _ channeltypes.Counterparty, version string) (string, error) {
+	return version, nil
+}
+
+func (externalStateMachine) OnChanOpenTry(_ string, _ channeltypes.Order, _ []string, _ string, _ string, _ channeltypes.Counterparty, counterpartyVersion string) (string, error) {
+	return counterpartyVersion, nil
+}
+
+func (externalStateMachine) OnChanOpenAck(_ string, _ string, _ string, _ string, _ string) error {
+	return nil
+}
+
+func (externalStateMachine) OnChanOpenConfirm(_ string, _ string, _ string) error {
+	return nil
+}
+
+// OnSendPacket validates and applies an outgoing packet, the external state machine can replace the data of the packet
+func (m externalStateMachine) OnSendPacket(chainName string, packet channeltypes.Packet) (channeltypes.Packet, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), stateMachineCallTimeout)
+	defer cancel()
+	res, err := m.client.OnSendPacket(ctx, &statemachine.OnSendPacketRequest{
+		ChainName: chainName,
+		Packet:    packet,
+	})
+	if err != nil {
+		return channeltypes.Packet{}, fmt.Errorf("the state machine rejected packet %d: %w", packet.Sequence, err)
+	}
+	if len(res.Data) != 0 {
+		packet.Data = res.Data
+	}
+
+	return packet, nil
+}
+
+// OnRecvPacket returns an error if the external state machine could not handle the packet at all (e.g. it is down), not if it rejected it
+func (m externalStateMachine) OnRecvPacket(chainName string, packet channeltypes.Packet) (exported.Acknowledgement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), stateMachineCallTimeout)
+	defer cancel()
+	res, err := m.client.OnRecvPacket(ctx, &statemachine.OnRecvPacketRequest{
+		ChainName: chainName,
+		Packet:    packet,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("the state machine failed to receive packet %d: %w", packet.Sequence, err)
+	}
+	if len(res.Acknowledgement) == 0 {
+		return nil, fmt.Errorf("the state machine returned an empty acknowledgement for packet %d", packet.Sequence)
+	}
+
+	return rawAcknowledgement(res.Acknowledgement), nil
+}
+
+func (m externalStateMachine) OnAcknowledgementPacket(chainName string, packet channeltypes.Packet, acknowledgement []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stateMachineCallTimeout)
+	defer cancel()
+	if _, err := m.client.OnAcknowledgementPacket(ctx, &statemachine.OnAcknowledgementPacketRequest{
+		ChainName:       chainName,
+		Packet:          packet,
+		Acknowledgement: acknowledgement,
+	}); err != nil {
+		return fmt.Errorf("the state machine failed to handle the acknowledgement of packet %d: %w", packet.Sequence, err)
+	}
+
+	return nil
+}
+
+func (m externalStateMachine) OnTimeoutPacket(chainName string, packet channeltypes.Packet) error {
+	ctx, cancel := context.WithTimeout(context.Background(), stateMachineCallTimeout)
+	defer cancel()
+	if _, err := m.client.OnTimeoutPacket(ctx, &statemachine.OnTimeoutPacketRequest{
+		ChainName: chainName,
+		Packet:    packet,
+	}); err != nil {
+		return fmt.Errorf("the state machine failed to time out packet %d: %w", packet.Sequence, err)
+	}
+
+	return nil
+}
+
+// rawAcknowledgement is an acknowledgement written by an application outside the solo machine, as the bytes it is committed with
+type rawAcknowledgement []byte
+
+// Success is only true for ICS-04 success acknowledgements, for other formats it is unknown and reported as false
+func (ack rawAcknowledgement) Success() bool {
+	var standardAck channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(ack, &standardAck); err != nil {
+		return false
+	}
+
+	return standardAck.Success()
+}
+
+func (ack rawAcknowledgement) Acknowledgement() []byte {
+	return ack
+}
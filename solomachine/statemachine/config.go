@@ -0,0 +1,48 @@
+// Package statemachine has the StateMachine gRPC service an external state machine implements (see proto/solomachine/statemachine),
+// and the client the solo machine calls it with. Go implementations need the gogoproto codec from the sdk on the server as well:
+// grpc.ForceServerCodec(codec.NewProtoCodec(codectypes.NewInterfaceRegistry()).GRPCCodec())
+package statemachine
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config configures the external state machine. The built-in ledger is used when no address is set.
+type Config struct {
+	GRPCAddr string   `yaml:"grpc-addr,omitempty"` // address of the StateMachine gRPC service (plaintext, so keep it on the same host or a private network)
+	Ports    []string `yaml:"ports,omitempty"`     // ports the state machine is bound to, only the transfer port if empty
+}
+
+func (c Config) Enabled() bool {
+	return c.GRPCAddr != ""
+}
+
+// BoundPorts returns the ports the state machine is bound to
+func (c Config) BoundPorts() []string {
+	if len(c.Ports) == 0 {
+		return []string{transfertypes.PortID}
+	}
+
+	return c.Ports
+}
+
+// NewClient creates a client for the StateMachine service, the connection is only made on the first call
+func NewClient(config Config) (StateMachineClient, error) {
+	// The messages are gogoproto messages, which need the codec from the sdk
+	grpcCodec := codec.NewProtoCodec(codectypes.NewInterfaceRegistry()).GRPCCodec()
+
+	conn, err := grpc.NewClient(
+		config.GRPCAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcCodec)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStateMachineClient(conn), nil
+}
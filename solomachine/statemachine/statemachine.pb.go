@@ -0,0 +1,1908 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: solomachine/statemachine/v1/statemachine.proto
+
+package statemachine
+
+import (
+	context "context"
+	fmt "fmt"
+	_ "github.com/cosmos/gogoproto/gogoproto"
+	grpc1 "github.com/cosmos/gogoproto/grpc"
+	proto "github.com/cosmos/gogoproto/proto"
+	types "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type OnSendPacketRequest struct {
+	// chain_name is the name of the chain in the solo machine config
+	ChainName string       `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Packet    types.Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet"`
+}
+
+func (m *OnSendPacketRequest) Reset()         { *m = OnSendPacketRequest{} }
+func (m *OnSendPacketRequest) String() string { return proto.CompactTextString(m) }
+func (*OnSendPacketRequest) ProtoMessage()    {}
+func (*OnSendPacketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{0}
+}
+func (m *OnSendPacketRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnSendPacketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnSendPacketRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnSendPacketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnSendPacketRequest.Merge(m, src)
+}
+func (m *OnSendPacketRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnSendPacketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnSendPacketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnSendPacketRequest proto.InternalMessageInfo
+
+func (m *OnSendPacketRequest) GetChainName() string {
+	if m != nil {
+		return m.ChainName
+	}
+	return ""
+}
+
+func (m *OnSendPacketRequest) GetPacket() types.Packet {
+	if m != nil {
+		return m.Packet
+	}
+	return types.Packet{}
+}
+
+type OnSendPacketResponse struct {
+	// data replaces the data of the packet, unless it is empty
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *OnSendPacketResponse) Reset()         { *m = OnSendPacketResponse{} }
+func (m *OnSendPacketResponse) String() string { return proto.CompactTextString(m) }
+func (*OnSendPacketResponse) ProtoMessage()    {}
+func (*OnSendPacketResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{1}
+}
+func (m *OnSendPacketResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnSendPacketResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnSendPacketResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnSendPacketResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnSendPacketResponse.Merge(m, src)
+}
+func (m *OnSendPacketResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnSendPacketResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnSendPacketResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnSendPacketResponse proto.InternalMessageInfo
+
+func (m *OnSendPacketResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type OnRecvPacketRequest struct {
+	ChainName string       `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Packet    types.Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet"`
+}
+
+func (m *OnRecvPacketRequest) Reset()         { *m = OnRecvPacketRequest{} }
+func (m *OnRecvPacketRequest) String() string { return proto.CompactTextString(m) }
+func (*OnRecvPacketRequest) ProtoMessage()    {}
+func (*OnRecvPacketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{2}
+}
+func (m *OnRecvPacketRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnRecvPacketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnRecvPacketRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnRecvPacketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnRecvPacketRequest.Merge(m, src)
+}
+func (m *OnRecvPacketRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnRecvPacketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnRecvPacketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnRecvPacketRequest proto.InternalMessageInfo
+
+func (m *OnRecvPacketRequest) GetChainName() string {
+	if m != nil {
+		return m.ChainName
+	}
+	return ""
+}
+
+func (m *OnRecvPacketRequest) GetPacket() types.Packet {
+	if m != nil {
+		return m.Packet
+	}
+	return types.Packet{}
+}
+
+type OnRecvPacketResponse struct {
+	// acknowledgement is written for the packet, it cannot be empty
+	Acknowledgement []byte `protobuf:"bytes,1,opt,name=acknowledgement,proto3" json:"acknowledgement,omitempty"`
+}
+
+func (m *OnRecvPacketResponse) Reset()         { *m = OnRecvPacketResponse{} }
+func (m *OnRecvPacketResponse) String() string { return proto.CompactTextString(m) }
+func (*OnRecvPacketResponse) ProtoMessage()    {}
+func (*OnRecvPacketResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{3}
+}
+func (m *OnRecvPacketResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnRecvPacketResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnRecvPacketResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnRecvPacketResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnRecvPacketResponse.Merge(m, src)
+}
+func (m *OnRecvPacketResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnRecvPacketResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnRecvPacketResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnRecvPacketResponse proto.InternalMessageInfo
+
+func (m *OnRecvPacketResponse) GetAcknowledgement() []byte {
+	if m != nil {
+		return m.Acknowledgement
+	}
+	return nil
+}
+
+type OnAcknowledgementPacketRequest struct {
+	ChainName       string       `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Packet          types.Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet"`
+	Acknowledgement []byte       `protobuf:"bytes,3,opt,name=acknowledgement,proto3" json:"acknowledgement,omitempty"`
+}
+
+func (m *OnAcknowledgementPacketRequest) Reset()         { *m = OnAcknowledgementPacketRequest{} }
+func (m *OnAcknowledgementPacketRequest) String() string { return proto.CompactTextString(m) }
+func (*OnAcknowledgementPacketRequest) ProtoMessage()    {}
+func (*OnAcknowledgementPacketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{4}
+}
+func (m *OnAcknowledgementPacketRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnAcknowledgementPacketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnAcknowledgementPacketRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnAcknowledgementPacketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnAcknowledgementPacketRequest.Merge(m, src)
+}
+func (m *OnAcknowledgementPacketRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnAcknowledgementPacketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnAcknowledgementPacketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnAcknowledgementPacketRequest proto.InternalMessageInfo
+
+func (m *OnAcknowledgementPacketRequest) GetChainName() string {
+	if m != nil {
+		return m.ChainName
+	}
+	return ""
+}
+
+func (m *OnAcknowledgementPacketRequest) GetPacket() types.Packet {
+	if m != nil {
+		return m.Packet
+	}
+	return types.Packet{}
+}
+
+func (m *OnAcknowledgementPacketRequest) GetAcknowledgement() []byte {
+	if m != nil {
+		return m.Acknowledgement
+	}
+	return nil
+}
+
+type OnAcknowledgementPacketResponse struct {
+}
+
+func (m *OnAcknowledgementPacketResponse) Reset()         { *m = OnAcknowledgementPacketResponse{} }
+func (m *OnAcknowledgementPacketResponse) String() string { return proto.CompactTextString(m) }
+func (*OnAcknowledgementPacketResponse) ProtoMessage()    {}
+func (*OnAcknowledgementPacketResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{5}
+}
+func (m *OnAcknowledgementPacketResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnAcknowledgementPacketResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnAcknowledgementPacketResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnAcknowledgementPacketResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnAcknowledgementPacketResponse.Merge(m, src)
+}
+func (m *OnAcknowledgementPacketResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnAcknowledgementPacketResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnAcknowledgementPacketResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnAcknowledgementPacketResponse proto.InternalMessageInfo
+
+type OnTimeoutPacketRequest struct {
+	ChainName string       `protobuf:"bytes,1,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	Packet    types.Packet `protobuf:"bytes,2,opt,name=packet,proto3" json:"packet"`
+}
+
+func (m *OnTimeoutPacketRequest) Reset()         { *m = OnTimeoutPacketRequest{} }
+func (m *OnTimeoutPacketRequest) String() string { return proto.CompactTextString(m) }
+func (*OnTimeoutPacketRequest) ProtoMessage()    {}
+func (*OnTimeoutPacketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{6}
+}
+func (m *OnTimeoutPacketRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnTimeoutPacketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnTimeoutPacketRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnTimeoutPacketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnTimeoutPacketRequest.Merge(m, src)
+}
+func (m *OnTimeoutPacketRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnTimeoutPacketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnTimeoutPacketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnTimeoutPacketRequest proto.InternalMessageInfo
+
+func (m *OnTimeoutPacketRequest) GetChainName() string {
+	if m != nil {
+		return m.ChainName
+	}
+	return ""
+}
+
+func (m *OnTimeoutPacketRequest) GetPacket() types.Packet {
+	if m != nil {
+		return m.Packet
+	}
+	return types.Packet{}
+}
+
+type OnTimeoutPacketResponse struct {
+}
+
+func (m *OnTimeoutPacketResponse) Reset()         { *m = OnTimeoutPacketResponse{} }
+func (m *OnTimeoutPacketResponse) String() string { return proto.CompactTextString(m) }
+func (*OnTimeoutPacketResponse) ProtoMessage()    {}
+func (*OnTimeoutPacketResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a1428ce3e614a48d, []int{7}
+}
+func (m *OnTimeoutPacketResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OnTimeoutPacketResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OnTimeoutPacketResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OnTimeoutPacketResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OnTimeoutPacketResponse.Merge(m, src)
+}
+func (m *OnTimeoutPacketResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *OnTimeoutPacketResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OnTimeoutPacketResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OnTimeoutPacketResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*OnSendPacketRequest)(nil), "solomachine.statemachine.v1.OnSendPacketRequest")
+	proto.RegisterType((*OnSendPacketResponse)(nil), "solomachine.statemachine.v1.OnSendPacketResponse")
+	proto.RegisterType((*OnRecvPacketRequest)(nil), "solomachine.statemachine.v1.OnRecvPacketRequest")
+	proto.RegisterType((*OnRecvPacketResponse)(nil), "solomachine.statemachine.v1.OnRecvPacketResponse")
+	proto.RegisterType((*OnAcknowledgementPacketRequest)(nil), "solomachine.statemachine.v1.OnAcknowledgementPacketRequest")
+	proto.RegisterType((*OnAcknowledgementPacketResponse)(nil), "solomachine.statemachine.v1.OnAcknowledgementPacketResponse")
+	proto.RegisterType((*OnTimeoutPacketRequest)(nil), "solomachine.statemachine.v1.OnTimeoutPacketRequest")
+	proto.RegisterType((*OnTimeoutPacketResponse)(nil), "solomachine.statemachine.v1.OnTimeoutPacketResponse")
+}
+
+func init() {
+	proto.RegisterFile("solomachine/statemachine/v1/statemachine.proto", fileDescriptor_a1428ce3e614a48d)
+}
+
+var fileDescriptor_a1428ce3e614a48d = []byte{
+	// 454 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xce, 0x42, 0x54, 0xa9, 0x43, 0xa4, 0x4a, 0x4b, 0x44, 0x8b, 0x2b, 0xdc, 0xd6, 0xa7, 0x08,
+	0x89, 0x35, 0x6e, 0xb9, 0x20, 0x90, 0x80, 0xde, 0x21, 0xc8, 0xe5, 0x02, 0x17, 0xb4, 0xde, 0x8c,
+	0x6c, 0xd3, 0x78, 0x37, 0xd8, 0x6b, 0x73, 0xe1, 0x15, 0x90, 0x38, 0xf0, 0x08, 0x3c, 0x4c, 0x8f,
+	0x3d, 0x72, 0x42, 0x28, 0x79, 0x11, 0x64, 0xaf, 0x0b, 0x75, 0xeb, 0x24, 0x4a, 0x0f, 0xb9, 0xcd,
+	0x8e, 0xe7, 0xfb, 0x59, 0xed, 0xe7, 0x01, 0x96, 0xa9, 0xb1, 0x4a, 0xb8, 0x88, 0x62, 0x89, 0x6e,
+	0xa6, 0xb9, 0xc6, 0x8b, 0x43, 0xe1, 0x35, 0xce, 0x6c, 0x92, 0x2a, 0xad, 0xe8, 0xee, 0xa5, 0x79,
+	0xd6, 0xf8, 0x5e, 0x78, 0x56, 0x3f, 0x54, 0xa1, 0xaa, 0xe6, 0xdc, 0xb2, 0x32, 0x10, 0xeb, 0x20,
+	0x0e, 0x84, 0x2b, 0x54, 0x8a, 0xae, 0x88, 0xb8, 0x94, 0x38, 0x2e, 0xa9, 0xeb, 0xd2, 0x8c, 0x38,
+	0x0a, 0xee, 0x0e, 0xe5, 0x09, 0xca, 0xd1, 0x5b, 0x2e, 0x4e, 0x51, 0xfb, 0xf8, 0x39, 0xc7, 0x4c,
+	0xd3, 0x07, 0x00, 0x22, 0xe2, 0xb1, 0xfc, 0x28, 0x79, 0x82, 0x3b, 0x64, 0x9f, 0x0c, 0x36, 0xfd,
+	0xcd, 0xaa, 0xf3, 0x86, 0x27, 0x48, 0x9f, 0xc2, 0xc6, 0xa4, 0x9a, 0xdf, 0xb9, 0xb5, 0x4f, 0x06,
+	0x77, 0x0e, 0x77, 0x59, 0x1c, 0x08, 0x56, 0x2a, 0xb1, 0x0b, 0xfa, 0xc2, 0x63, 0x86, 0xf2, 0xb8,
+	0x7b, 0xf6, 0x7b, 0xaf, 0xe3, 0xd7, 0x00, 0xe7, 0x21, 0xf4, 0x9b, 0x82, 0xd9, 0x44, 0xc9, 0x0c,
+	0x29, 0x85, 0xee, 0x88, 0x6b, 0x5e, 0x69, 0xf5, 0xfc, 0xaa, 0x36, 0xe6, 0x7c, 0x14, 0xc5, 0xba,
+	0xcc, 0xbd, 0x84, 0x7e, 0x53, 0xb0, 0x36, 0x37, 0x80, 0x2d, 0x2e, 0x4e, 0xa5, 0xfa, 0x32, 0xc6,
+	0x51, 0x88, 0x09, 0x4a, 0x5d, 0xfb, 0xbc, 0xda, 0x76, 0x7e, 0x12, 0xb0, 0x87, 0xf2, 0x55, 0xb3,
+	0xbb, 0x26, 0xfb, 0x6d, 0x36, 0x6f, 0xb7, 0xdb, 0x3c, 0x80, 0xbd, 0xb9, 0x2e, 0xcd, 0x9d, 0x9d,
+	0x14, 0xee, 0x0d, 0xe5, 0xbb, 0x38, 0x41, 0x95, 0xaf, 0xeb, 0x02, 0xce, 0x7d, 0xd8, 0xbe, 0xa6,
+	0x69, 0xec, 0x1c, 0x7e, 0xeb, 0x42, 0xef, 0x44, 0x73, 0x8d, 0xaf, 0x4d, 0xea, 0x69, 0x06, 0xbd,
+	0xcb, 0x41, 0xa2, 0x8f, 0xd9, 0x82, 0x1f, 0x84, 0xb5, 0x84, 0xdc, 0xf2, 0x56, 0x40, 0xd4, 0x41,
+	0xa8, 0x44, 0xff, 0x07, 0x64, 0xa9, 0xe8, 0xb5, 0xf0, 0x5a, 0xde, 0x0a, 0x88, 0x5a, 0xf4, 0x07,
+	0x81, 0xed, 0x39, 0xaf, 0x45, 0x9f, 0x2d, 0xa1, 0x5b, 0x94, 0x44, 0xeb, 0xf9, 0xcd, 0xc0, 0xb5,
+	0xad, 0xaf, 0xb0, 0x75, 0xe5, 0xb1, 0xe8, 0xd1, 0x12, 0xc2, 0xb6, 0x38, 0x59, 0x4f, 0x56, 0x03,
+	0x19, 0xf5, 0xe3, 0xf7, 0x67, 0x53, 0x9b, 0x9c, 0x4f, 0x6d, 0xf2, 0x67, 0x6a, 0x93, 0xef, 0x33,
+	0xbb, 0x73, 0x3e, 0xb3, 0x3b, 0xbf, 0x66, 0x76, 0xe7, 0xc3, 0x8b, 0x30, 0xd6, 0x51, 0x1e, 0x30,
+	0xa1, 0x12, 0x37, 0xfc, 0x84, 0x69, 0x92, 0xcb, 0x51, 0xc8, 0x53, 0x1e, 0x70, 0xb7, 0x14, 0x7a,
+	0xf4, 0x6f, 0xe7, 0xce, 0xd9, 0xbf, 0xc1, 0x46, 0xb5, 0x1a, 0x8f, 0xfe, 0x0e, 0x00, 0x44, 0xcf,
+	0x4c, 0x2b, 0xa2, 0x05, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// StateMachineClient is the client API for StateMachine service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StateMachineClient interface {
+	// OnSendPacket validates an outgoing packet and applies it (e.g. takes the tokens from the sender), before the
+	// solo machine commits to the packet. It can build the data of the packet, by returning the data to send instead.
+	// If the solo machine cannot store the packet afterwards, it calls OnTimeoutPacket with the packet to reverse it.
+	OnSendPacket(ctx context.Context, in *OnSendPacketRequest, opts ...grpc.CallOption) (*OnSendPacketResponse, error)
+	// OnRecvPacket applies a packet received from the chain and returns the acknowledgement for it.
+	// A packet that should be rejected gets an error acknowledgement.
+	OnRecvPacket(ctx context.Context, in *OnRecvPacketRequest, opts ...grpc.CallOption) (*OnRecvPacketResponse, error)
+	// OnAcknowledgementPacket handles the acknowledgement the chain wrote for a packet sent by the solo machine
+	// (e.g. refunds the tokens on an error acknowledgement).
+	OnAcknowledgementPacket(ctx context.Context, in *OnAcknowledgementPacketRequest, opts ...grpc.CallOption) (*OnAcknowledgementPacketResponse, error)
+	// OnTimeoutPacket reverses a packet sent by the solo machine that timed out on the chain (e.g. refunds the tokens).
+	// It is also called for a packet that was applied by OnSendPacket but could not be stored, which is never sent.
+	OnTimeoutPacket(ctx context.Context, in *OnTimeoutPacketRequest, opts ...grpc.CallOption) (*OnTimeoutPacketResponse, error)
+}
+
+type stateMachineClient struct {
+	cc grpc1.ClientConn
+}
+
+func NewStateMachineClient(cc grpc1.ClientConn) StateMachineClient {
+	return &stateMachineClient{cc}
+}
+
+func (c *stateMachineClient) OnSendPacket(ctx context.Context, in *OnSendPacketRequest, opts ...grpc.CallOption) (*OnSendPacketResponse, error) {
+	out := new(OnSendPacketResponse)
+	err := c.cc.Invoke(ctx, "/solomachine.statemachine.v1.StateMachine/OnSendPacket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateMachineClient) OnRecvPacket(ctx context.Context, in *OnRecvPacketRequest, opts ...grpc.CallOption) (*OnRecvPacketResponse, error) {
+	out := new(OnRecvPacketResponse)
+	err := c.cc.Invoke(ctx, "/solomachine.statemachine.v1.StateMachine/OnRecvPacket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateMachineClient) OnAcknowledgementPacket(ctx context.Context, in *OnAcknowledgementPacketRequest, opts ...grpc.CallOption) (*OnAcknowledgementPacketResponse, error) {
+	out := new(OnAcknowledgementPacketResponse)
+	err := c.cc.Invoke(ctx, "/solomachine.statemachine.v1.StateMachine/OnAcknowledgementPacket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stateMachineClient) OnTimeoutPacket(ctx context.Context, in *OnTimeoutPacketRequest, opts ...grpc.CallOption) (*OnTimeoutPacketResponse, error) {
+	out := new(OnTimeoutPacketResponse)
+	err := c.cc.Invoke(ctx, "/solomachine.statemachine.v1.StateMachine/OnTimeoutPacket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StateMachineServer is the server API for StateMachine service.
+type StateMachineServer interface {
+	// OnSendPacket validates an outgoing packet and applies it (e.g. takes the tokens from the sender), before the
+	// solo machine commits to the packet. It can build the data of the packet, by returning the data to send instead.
+	// If the solo machine cannot store the packet afterwards, it calls OnTimeoutPacket with the packet to reverse it.
+	OnSendPacket(context.Context, *OnSendPacketRequest) (*OnSendPacketResponse, error)
+	// OnRecvPacket applies a packet received from the chain and returns the acknowledgement for it.
+	// A packet that should be rejected gets an error acknowledgement.
+	OnRecvPacket(context.Context, *OnRecvPacketRequest) (*OnRecvPacketResponse, error)
+	// OnAcknowledgementPacket handles the acknowledgement the chain wrote for a packet sent by the solo machine
+	// (e.g. refunds the tokens on an error acknowledgement).
+	OnAcknowledgementPacket(context.Context, *OnAcknowledgementPacketRequest) (*OnAcknowledgementPacketResponse, error)
+	// OnTimeoutPacket reverses a packet sent by the solo machine that timed out on the chain (e.g. refunds the tokens).
+	// It is also called for a packet that was applied by OnSendPacket but could not be stored, which is never sent.
+	OnTimeoutPacket(context.Context, *OnTimeoutPacketRequest) (*OnTimeoutPacketResponse, error)
+}
+
+// UnimplementedStateMachineServer can be embedded to have forward compatible implementations.
+type UnimplementedStateMachineServer struct {
+}
+
+func (*UnimplementedStateMachineServer) OnSendPacket(ctx context.Context, req *OnSendPacketRequest) (*OnSendPacketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnSendPacket not implemented")
+}
+func (*UnimplementedStateMachineServer) OnRecvPacket(ctx context.Context, req *OnRecvPacketRequest) (*OnRecvPacketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnRecvPacket not implemented")
+}
+func (*UnimplementedStateMachineServer) OnAcknowledgementPacket(ctx context.Context, req *OnAcknowledgementPacketRequest) (*OnAcknowledgementPacketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnAcknowledgementPacket not implemented")
+}
+func (*UnimplementedStateMachineServer) OnTimeoutPacket(ctx context.Context, req *OnTimeoutPacketRequest) (*OnTimeoutPacketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method OnTimeoutPacket not implemented")
+}
+
+func RegisterStateMachineServer(s grpc1.Server, srv StateMachineServer) {
+	s.RegisterService(&_StateMachine_serviceDesc, srv)
+}
+
+func _StateMachine_OnSendPacket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnSendPacketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateMachineServer).OnSendPacket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/solomachine.statemachine.v1.StateMachine/OnSendPacket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateMachineServer).OnSendPacket(ctx, req.(*OnSendPacketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateMachine_OnRecvPacket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnRecvPacketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateMachineServer).OnRecvPacket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/solomachine.statemachine.v1.StateMachine/OnRecvPacket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateMachineServer).OnRecvPacket(ctx, req.(*OnRecvPacketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateMachine_OnAcknowledgementPacket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnAcknowledgementPacketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateMachineServer).OnAcknowledgementPacket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/solomachine.statemachine.v1.StateMachine/OnAcknowledgementPacket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateMachineServer).OnAcknowledgementPacket(ctx, req.(*OnAcknowledgementPacketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StateMachine_OnTimeoutPacket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnTimeoutPacketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StateMachineServer).OnTimeoutPacket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/solomachine.statemachine.v1.StateMachine/OnTimeoutPacket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StateMachineServer).OnTimeoutPacket(ctx, req.(*OnTimeoutPacketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _StateMachine_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "solomachine.statemachine.v1.StateMachine",
+	HandlerType: (*StateMachineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "OnSendPacket",
+			Handler:    _StateMachine_OnSendPacket_Handler,
+		},
+		{
+			MethodName: "OnRecvPacket",
+			Handler:    _StateMachine_OnRecvPacket_Handler,
+		},
+		{
+			MethodName: "OnAcknowledgementPacket",
+			Handler:    _StateMachine_OnAcknowledgementPacket_Handler,
+		},
+		{
+			MethodName: "OnTimeoutPacket",
+			Handler:    _StateMachine_OnTimeoutPacket_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "solomachine/statemachine/v1/statemachine.proto",
+}
+
+func (m *OnSendPacketRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnSendPacketRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnSendPacketRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.Packet.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStatemachine(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	if len(m.ChainName) > 0 {
+		i -= len(m.ChainName)
+		copy(dAtA[i:], m.ChainName)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.ChainName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnSendPacketResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnSendPacketResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnSendPacketResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnRecvPacketRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnRecvPacketRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnRecvPacketRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.Packet.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStatemachine(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	if len(m.ChainName) > 0 {
+		i -= len(m.ChainName)
+		copy(dAtA[i:], m.ChainName)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.ChainName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnRecvPacketResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnRecvPacketResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnRecvPacketResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Acknowledgement) > 0 {
+		i -= len(m.Acknowledgement)
+		copy(dAtA[i:], m.Acknowledgement)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.Acknowledgement)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnAcknowledgementPacketRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnAcknowledgementPacketRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnAcknowledgementPacketRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Acknowledgement) > 0 {
+		i -= len(m.Acknowledgement)
+		copy(dAtA[i:], m.Acknowledgement)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.Acknowledgement)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	{
+		size, err := m.Packet.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStatemachine(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	if len(m.ChainName) > 0 {
+		i -= len(m.ChainName)
+		copy(dAtA[i:], m.ChainName)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.ChainName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnAcknowledgementPacketResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnAcknowledgementPacketResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnAcknowledgementPacketResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *OnTimeoutPacketRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnTimeoutPacketRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnTimeoutPacketRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.Packet.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintStatemachine(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	if len(m.ChainName) > 0 {
+		i -= len(m.ChainName)
+		copy(dAtA[i:], m.ChainName)
+		i = encodeVarintStatemachine(dAtA, i, uint64(len(m.ChainName)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OnTimeoutPacketResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OnTimeoutPacketResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OnTimeoutPacketResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintStatemachine(dAtA []byte, offset int, v uint64) int {
+	offset -= sovStatemachine(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *OnSendPacketRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ChainName)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	l = m.Packet.Size()
+	n += 1 + l + sovStatemachine(uint64(l))
+	return n
+}
+
+func (m *OnSendPacketResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	return n
+}
+
+func (m *OnRecvPacketRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ChainName)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	l = m.Packet.Size()
+	n += 1 + l + sovStatemachine(uint64(l))
+	return n
+}
+
+func (m *OnRecvPacketResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Acknowledgement)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	return n
+}
+
+func (m *OnAcknowledgementPacketRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ChainName)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	l = m.Packet.Size()
+	n += 1 + l + sovStatemachine(uint64(l))
+	l = len(m.Acknowledgement)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	return n
+}
+
+func (m *OnAcknowledgementPacketResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *OnTimeoutPacketRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ChainName)
+	if l > 0 {
+		n += 1 + l + sovStatemachine(uint64(l))
+	}
+	l = m.Packet.Size()
+	n += 1 + l + sovStatemachine(uint64(l))
+	return n
+}
+
+func (m *OnTimeoutPacketResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func sovStatemachine(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozStatemachine(x uint64) (n int) {
+	return sovStatemachine(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *OnSendPacketRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnSendPacketRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnSendPacketRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChainName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChainName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Packet", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Packet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnSendPacketResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnSendPacketResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnSendPacketResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnRecvPacketRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnRecvPacketRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnRecvPacketRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChainName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChainName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Packet", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Packet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnRecvPacketResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnRecvPacketResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnRecvPacketResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Acknowledgement", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Acknowledgement = append(m.Acknowledgement[:0], dAtA[iNdEx:postIndex]...)
+			if m.Acknowledgement == nil {
+				m.Acknowledgement = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnAcknowledgementPacketRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnAcknowledgementPacketRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnAcknowledgementPacketRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChainName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChainName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Packet", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Packet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Acknowledgement", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Acknowledgement = append(m.Acknowledgement[:0], dAtA[iNdEx:postIndex]...)
+			if m.Acknowledgement == nil {
+				m.Acknowledgement = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnAcknowledgementPacketResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnAcknowledgementPacketResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnAcknowledgementPacketResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnTimeoutPacketRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnTimeoutPacketRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnTimeoutPacketRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChainName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChainName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Packet", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Packet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OnTimeoutPacketResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OnTimeoutPacketResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OnTimeoutPacketResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipStatemachine(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthStatemachine
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipStatemachine(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowStatemachine
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowStatemachine
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthStatemachine
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupStatemachine
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthStatemachine
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthStatemachine        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowStatemachine          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupStatemachine = fmt.Errorf("proto: unexpected end of group")
+)
@@ -9,6 +9,8 @@ import (
 
 type Status struct {
 	Diversifier string
+	PublicKey   string
+	SeparateKey bool
 
 	LightClientID                   string
 	LightClientLatestHeight         clienttypes.Height
@@ -26,6 +28,11 @@ type Status struct {
 func (sm *SoloMachine) Status(chainName string) (Status, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
+	pubKey, err := sm.ChainPublicKey(chainName)
+	if err != nil {
+		return Status{}, err
+	}
+
 	var lightClientLatestHeight clienttypes.Height
 	if chainStorage.LightClientExists() {
 		lightClientState, err := chainStorage.LightClientState()
@@ -75,6 +82,8 @@ func (sm *SoloMachine) Status(chainName string) (Status, error) {
 
 	return Status{
 		Diversifier:                     chainStorage.Diversifier(),
+		PublicKey:                       pubKey.String(),
+		SeparateKey:                     chainStorage.HasPrivateKey(),
 		LightClientID:                   chainStorage.ClientID(),
 		LightClientLatestHeight:         lightClientLatestHeight,
 		CounterpartyActualHeight:        counterpartyActualHeight,
@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"bytes"
+	storetypes "cosmossdk.io/store/types"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+)
+
+// Counters are the next identifier numbers for light clients, connections and channels created by the solo machine
+type Counters struct {
+	NextLightClientNumber uint64 `json:"next_light_client_number"`
+	NextConnectionNumber  uint64 `json:"next_connection_number"`
+	NextChannelNumber     uint64 `json:"next_channel_number"`
+}
+
+// ChainBackup is the state of a chain that is needed to keep using its clients, connections and channels after a recovery
+// The tendermint light client itself is not part of it, it is created again (with the same client id) by init.
+type ChainBackup struct {
+	Diversifier              string `json:"diversifier"`
+	PendingDiversifier       string `json:"pending_diversifier,omitempty"`
+	ClientID                 string `json:"client_id,omitempty"`
+	CounterpartyClientID     string `json:"counterparty_client_id,omitempty"`
+	ConnectionID             string `json:"connection_id,omitempty"`
+	CounterpartyConnectionID string `json:"counterparty_connection_id,omitempty"`
+	ICS20Channel             string `json:"ics20_channel,omitempty"`
+	CounterpartyICS20Channel string `json:"counterparty_ics20_channel,omitempty"`
+	// The packet sequences of the ICS20 channel, so packet sequences are not reused after a recovery
+	ICS20NextSequenceSend uint64 `json:"ics20_next_sequence_send,omitempty"`
+	ICS20NextSequenceRecv uint64 `json:"ics20_next_sequence_recv,omitempty"`
+	ICS20NextSequenceAck  uint64 `json:"ics20_next_sequence_ack,omitempty"`
+	// Channels are the solo machine ends of all the channels (the ICS20 channel too, if its end is stored)
+	Channels []ChannelBackup `json:"channels,omitempty"`
+	// PacketState are the receipts, acknowledgements, commitments, sent packets and timeouts of all the channels, as they are stored,
+	// so packets are not received (and e.g. credited) again, and pending packets can still be acknowledged or timed out after a recovery
+	PacketState []StoreEntry `json:"packet_state,omitempty"`
+	// InterchainAccounts are the channels and addresses of the interchain account controller ports, so the accounts can still be used
+	// after a recovery (the host does not allow a new channel for a port while the old one is active)
+	InterchainAccounts []InterchainAccountBackup `json:"interchain_accounts,omitempty"`
+}
+
+// InterchainAccountBackup is the channel of an interchain account controller port, and the address of the account if it is registered
+type InterchainAccountBackup struct {
+	PortID    string `json:"port_id"`
+	ChannelID string `json:"channel_id"`
+	Address   string `json:"address,omitempty"`
+}
+
+// StoreEntry is a key and its value in a backup of a store
+type StoreEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// ChannelBackup is the solo machine end of a channel with its packet sequences
+type ChannelBackup struct {
+	Channel          channeltypes.IdentifiedChannel `json:"channel"`
+	NextSequenceSend uint64                         `json:"next_sequence_send"`
+	NextSequenceRecv uint64                         `json:"next_sequence_recv"`
+	NextSequenceAck  uint64                         `json:"next_sequence_ack"`
+}
+
+func (s *Storage) Counters() Counters {
+	return Counters{
+		NextLightClientNumber: s.nextLightClientNumber,
+		NextConnectionNumber:  s.nextConnectionNumber,
+		NextChannelNumber:     s.nextChannelNumber,
+	}
+}
+
+// RestoreCounters sets the counters from a backup, they are never moved backwards so identifiers are not reused
+func (s *Storage) RestoreCounters(counters Counters) {
+	soloMachineStorage := s.getSoloMachineStorage()
+
+	s.nextLightClientNumber = max(s.nextLightClientNumber, counters.NextLightClientNumber)
+	s.nextConnectionNumber = max(s.nextConnectionNumber, counters.NextConnectionNumber)
+	s.nextChannelNumber = max(s.nextChannelNumber, counters.NextChannelNumber)
+
+	soloMachineStorage.Set([]byte(nextLightClientNumberKey), sdk.Uint64ToBigEndian(s.nextLightClientNumber))
+	soloMachineStorage.Set([]byte(nextConnectionNumberKey), sdk.Uint64ToBigEndian(s.nextConnectionNumber))
+	soloMachineStorage.Set([]byte(nextChannelNumberKey), sdk.Uint64ToBigEndian(s.nextChannelNumber))
+	s.Commit()
+}
+
+func (cs *ChainStorage) Backup() (ChainBackup, error) {
+	pendingDiversifier, _ := cs.PendingDiversifier()
+
+	backup := ChainBackup{
+		Diversifier:              cs.diversifier,
+		PendingDiversifier:       pendingDiversifier,
+		ClientID:                 cs.clientID,
+		CounterpartyClientID:     cs.counterpartyClientID,
+		ConnectionID:             cs.connectionID,
+		CounterpartyConnectionID: cs.counterpartyConnectionID,
+		ICS20Channel:             cs.ics20Channel,
+		CounterpartyICS20Channel: cs.counterpartyICS20Channel,
+	}
+	if cs.ics20Channel != "" {
+		backup.ICS20NextSequenceSend = cs.NextSequenceSend(transfertypes.PortID, cs.ics20Channel)
+		backup.ICS20NextSequenceRecv = cs.NextSequenceRecv(transfertypes.PortID, cs.ics20Channel)
+		backup.ICS20NextSequenceAck = cs.NextSequenceAck(transfertypes.PortID, cs.ics20Channel)
+	}
+
+	channels, err := cs.Channels()
+	if err != nil {
+		return ChainBackup{}, err
+	}
+	for _, channel := range channels {
+		backup.Channels = append(backup.Channels, ChannelBackup{
+			Channel:          channel,
+			NextSequenceSend: cs.NextSequenceSend(channel.PortId, channel.ChannelId),
+			NextSequenceRecv: cs.NextSequenceRecv(channel.PortId, channel.ChannelId),
+			NextSequenceAck:  cs.NextSequenceAck(channel.PortId, channel.ChannelId),
+		})
+	}
+
+	// The next sequences are in the channel backups
+	iterator := storetypes.KVStorePrefixIterator(cs.packetsStore(), nil)
+	defer iterator.Close()
+	for ; iterator.Valid(); iterator.Next() {
+		if isNextSequenceKey(iterator.Key()) {
+			continue
+		}
+		backup.PacketState = append(backup.PacketState, StoreEntry{Key: string(iterator.Key()), Value: iterator.Value()})
+	}
+
+	icaIterator := storetypes.KVStorePrefixIterator(cs.interchainAccountChannelsStore(), nil)
+	defer icaIterator.Close()
+	for ; icaIterator.Valid(); icaIterator.Next() {
+		portID := string(icaIterator.Key())
+		address, _ := cs.InterchainAccountAddress(portID)
+		backup.InterchainAccounts = append(backup.InterchainAccounts, InterchainAccountBackup{
+			PortID:    portID,
+			ChannelID: string(icaIterator.Value()),
+			Address:   address,
+		})
+	}
+
+	return backup, nil
+}
+
+// Restore sets the chain state from a backup, it can only be done before anything has been set up for the chain
+func (cs *ChainStorage) Restore(backup ChainBackup) error {
+	if cs.clientID != "" || cs.counterpartyClientID != "" {
+		return fmt.Errorf("the chain has already been set up, it can only be restored in a fresh home directory")
+	}
+	if backup.Diversifier == "" {
+		return fmt.Errorf("the backup has no diversifier")
+	}
+
+	values := map[string]string{
+		diversifierKey:              backup.Diversifier,
+		pendingDiversifierKey:       backup.PendingDiversifier,
+		clientIDKey:                 backup.ClientID,
+		counterpartyClientIDKey:     backup.CounterpartyClientID,
+		connectionIDKey:             backup.ConnectionID,
+		counterpartyConnectionIDKey: backup.CounterpartyConnectionID,
+		ics20ChannelKey:             backup.ICS20Channel,
+		counterpartyICS20ChannelKey: backup.CounterpartyICS20Channel,
+	}
+	for key, value := range values {
+		if value == "" {
+			continue
+		}
+		cs.store.Set([]byte(key), []byte(value))
+	}
+	for _, channelBackup := range backup.Channels {
+		channel := channelBackup.Channel
+		if err := host.PortIdentifierValidator(channel.PortId); err != nil {
+			return fmt.Errorf("invalid port %s in the backup: %w", channel.PortId, err)
+		}
+		if err := host.ChannelIdentifierValidator(channel.ChannelId); err != nil {
+			return fmt.Errorf("invalid channel %s in the backup: %w", channel.ChannelId, err)
+		}
+		if err := channelEnd(channel).ValidateBasic(); err != nil {
+			return fmt.Errorf("invalid channel %s in the backup: %w", channel.ChannelId, err)
+		}
+	}
+
+	for _, entry := range backup.PacketState {
+		if entry.Key == "" || len(entry.Value) == 0 || isNextSequenceKey([]byte(entry.Key)) {
+			return fmt.Errorf("invalid packet state entry %q in the backup", entry.Key)
+		}
+	}
+
+	for _, account := range backup.InterchainAccounts {
+		if err := host.PortIdentifierValidator(account.PortID); err != nil {
+			return fmt.Errorf("invalid interchain account port %s in the backup: %w", account.PortID, err)
+		}
+		if err := host.ChannelIdentifierValidator(account.ChannelID); err != nil {
+			return fmt.Errorf("invalid interchain account channel %s in the backup: %w", account.ChannelID, err)
+		}
+	}
+
+	packetsStore := cs.packetsStore()
+	for _, entry := range backup.PacketState {
+		packetsStore.Set([]byte(entry.Key), entry.Value)
+	}
+	if backup.ICS20Channel != "" {
+		packetsStore.Set(host.NextSequenceSendKey(transfertypes.PortID, backup.ICS20Channel), sdk.Uint64ToBigEndian(max(backup.ICS20NextSequenceSend, 1)))
+		packetsStore.Set(host.NextSequenceRecvKey(transfertypes.PortID, backup.ICS20Channel), sdk.Uint64ToBigEndian(max(backup.ICS20NextSequenceRecv, 1)))
+		packetsStore.Set(host.NextSequenceAckKey(transfertypes.PortID, backup.ICS20Channel), sdk.Uint64ToBigEndian(max(backup.ICS20NextSequenceAck, 1)))
+	}
+	for _, channelBackup := range backup.Channels {
+		channel := channelBackup.Channel
+		cs.setChannel(channel.PortId, channel.ChannelId, channelEnd(channel))
+		packetsStore.Set(host.NextSequenceSendKey(channel.PortId, channel.ChannelId), sdk.Uint64ToBigEndian(max(channelBackup.NextSequenceSend, 1)))
+		packetsStore.Set(host.NextSequenceRecvKey(channel.PortId, channel.ChannelId), sdk.Uint64ToBigEndian(max(channelBackup.NextSequenceRecv, 1)))
+		packetsStore.Set(host.NextSequenceAckKey(channel.PortId, channel.ChannelId), sdk.Uint64ToBigEndian(max(channelBackup.NextSequenceAck, 1)))
+	}
+
+	for _, account := range backup.InterchainAccounts {
+		cs.SetInterchainAccountChannelID(account.PortID, account.ChannelID)
+		if account.Address != "" {
+			cs.SetInterchainAccountAddress(account.PortID, account.Address)
+		}
+	}
+
+	if backup.CounterpartyClientID != "" {
+		cs.parent.getSoloMachineStorage().Set([]byte(clientChainsPrefix+cs.chainName), []byte{byte(1)})
+	}
+
+	cs.diversifier = backup.Diversifier
+	cs.clientID = backup.ClientID
+	cs.counterpartyClientID = backup.CounterpartyClientID
+	cs.connectionID = backup.ConnectionID
+	cs.counterpartyConnectionID = backup.CounterpartyConnectionID
+	cs.ics20Channel = backup.ICS20Channel
+	cs.counterpartyICS20Channel = backup.CounterpartyICS20Channel
+	cs.parent.Commit()
+
+	return nil
+}
+
+// channelEnd turns an identified channel back into the channel end that is stored
+func channelEnd(channel channeltypes.IdentifiedChannel) channeltypes.Channel {
+	return channeltypes.Channel{
+		State:           channel.State,
+		Ordering:        channel.Ordering,
+		Counterparty:    channel.Counterparty,
+		ConnectionHops:  channel.ConnectionHops,
+		Version:         channel.Version,
+		UpgradeSequence: channel.UpgradeSequence,
+	}
+}
+
+func isNextSequenceKey(key []byte) bool {
+	return bytes.HasPrefix(key, []byte(host.KeyNextSeqSendPrefix+"/")) ||
+		bytes.HasPrefix(key, []byte(host.KeyNextSeqRecvPrefix+"/")) ||
+		bytes.HasPrefix(key, []byte(host.KeyNextSeqAckPrefix+"/"))
+}
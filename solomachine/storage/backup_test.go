@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestChainBackupInterchainAccounts(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	cs.SetCounterPartyClientID("06-solomachine-0")
+	cs.SetInterchainAccountChannelID("icacontroller-alice", "channel-1")
+	cs.SetInterchainAccountAddress("icacontroller-alice", "cosmos1ica")
+	cs.SetInterchainAccountChannelID("icacontroller-bob", "channel-2")
+	cs.parent.Commit()
+
+	backup, err := cs.Backup()
+	require.NoError(t, err)
+	require.Equal(t, []InterchainAccountBackup{
+		{PortID: "icacontroller-alice", ChannelID: "channel-1", Address: "cosmos1ica"},
+		{PortID: "icacontroller-bob", ChannelID: "channel-2"},
+	}, backup.InterchainAccounts)
+
+	restored := newTestStorage(t).GetChainStorage(testChainName)
+	require.NoError(t, restored.Restore(backup))
+	channelID, ok := restored.InterchainAccountChannelID("icacontroller-alice")
+	require.True(t, ok)
+	require.Equal(t, "channel-1", channelID)
+	address, ok := restored.InterchainAccountAddress("icacontroller-alice")
+	require.True(t, ok)
+	require.Equal(t, "cosmos1ica", address)
+	_, ok = restored.InterchainAccountAddress("icacontroller-bob")
+	require.False(t, ok, "an account that was not registered yet has no address")
+
+	backup.InterchainAccounts[0].ChannelID = "not a channel"
+	require.Error(t, newTestStorage(t).GetChainStorage(testChainName).Restore(backup))
+}
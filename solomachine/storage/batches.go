@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"cosmossdk.io/store/prefix"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+)
+
+// transferBatchesPrefix keeps the packet sequence each row of a transfer batch was sent with, as transfer-batches/<batch-id>/<row>
+const transferBatchesPrefix = "transfer-batches/"
+
+func (cs *ChainStorage) transferBatchStore(batchID string) prefix.Store {
+	return prefix.NewStore(cs.store, []byte(fmt.Sprintf("%s%s/", transferBatchesPrefix, batchID)))
+}
+
+// TransferBatchPacketSequence returns the sequence of the packet a row of a transfer batch was sent with, if it has been sent
+func (cs *ChainStorage) TransferBatchPacketSequence(batchID string, row int) (uint64, bool) {
+	bz := cs.transferBatchStore(batchID).Get(sdk.Uint64ToBigEndian(uint64(row)))
+	if bz == nil {
+		return 0, false
+	}
+
+	return sdk.BigEndianToUint64(bz), true
+}
+
+// SendTransferBatchPackets stores the packets for rows of a transfer batch (like SendPacket) and which packet each row was sent with (in a single commit)
+// The packets must be on the same channel, with consecutive sequences starting at the next send sequence.
+func (cs *ChainStorage) SendTransferBatchPackets(batchID string, rows []int, packets []channeltypes.Packet, commitments [][]byte) error {
+	if len(rows) != len(packets) || len(packets) != len(commitments) {
+		return fmt.Errorf("got %d rows, %d packets and %d commitments", len(rows), len(packets), len(commitments))
+	}
+	if len(packets) == 0 {
+		return nil
+	}
+
+	// Checked up front, so nothing is written for a batch that cannot be sent
+	nextSequenceSend := cs.NextSequenceSend(packets[0].SourcePort, packets[0].SourceChannel)
+	for i, packet := range packets {
+		if packet.SourcePort != packets[0].SourcePort || packet.SourceChannel != packets[0].SourceChannel || packet.Sequence != nextSequenceSend+uint64(i) {
+			return fmt.Errorf("packet %d (sequence %d on %s/%s) does not follow the next send sequence %d of %s/%s",
+				i, packet.Sequence, packet.SourcePort, packet.SourceChannel, nextSequenceSend, packets[0].SourcePort, packets[0].SourceChannel)
+		}
+	}
+
+	store := cs.transferBatchStore(batchID)
+	for i, packet := range packets {
+		if err := cs.sendPacket(packet, commitments[i]); err != nil {
+			return err
+		}
+		store.Set(sdk.Uint64ToBigEndian(uint64(rows[i])), sdk.Uint64ToBigEndian(packet.Sequence))
+	}
+	cs.parent.Commit()
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"fmt"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// HasPrivateKey returns true if the chain has its own signing key, instead of using the shared solo machine key
+func (cs *ChainStorage) HasPrivateKey() bool {
+	return cs.parent.getSoloMachineStorage().Has(cs.chainKey(privateKeyKey))
+}
+
+// PrivateKey returns the signing key of the chain, decrypted with the passphrase
+func (cs *ChainStorage) PrivateKey(passphrase string) (cryptotypes.PrivKey, error) {
+	return cs.parent.getEncryptedKey(string(cs.chainKey(privateKeyKey)), passphrase)
+}
+
+// Mnemonic returns the mnemonic the signing key of the chain was derived from, if it was created from one
+func (cs *ChainStorage) Mnemonic(passphrase string) (string, bool, error) {
+	return cs.parent.getEncryptedMnemonic(string(cs.chainKey(mnemonicKey)), passphrase)
+}
+
+// CreatePrivateKey stores a signing key for the chain, encrypted with the passphrase
+// It can only be used before the counterparty light client is created, existing clients must be moved to a new key with a key rotation.
+func (cs *ChainStorage) CreatePrivateKey(privKey cryptotypes.PrivKey, mnemonic string, passphrase string) error {
+	if cs.HasPrivateKey() {
+		return fmt.Errorf("chain %s already has its own key", cs.chainName)
+	}
+
+	if err := cs.parent.setEncryptedMnemonic(string(cs.chainKey(mnemonicKey)), mnemonic, passphrase); err != nil {
+		return err
+	}
+	return cs.parent.setEncryptedKey(string(cs.chainKey(privateKeyKey)), privKey, passphrase)
+}
+
+// PendingPrivateKey returns the key that is being rotated in for the chain, if a key rotation is in progress
+func (cs *ChainStorage) PendingPrivateKey(passphrase string) (cryptotypes.PrivKey, bool, error) {
+	if !cs.parent.getSoloMachineStorage().Has(cs.chainKey(pendingPrivateKeyKey)) {
+		return nil, false, nil
+	}
+
+	privKey, err := cs.parent.getEncryptedKey(string(cs.chainKey(pendingPrivateKeyKey)), passphrase)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return privKey, true, nil
+}
+
+// SetPendingPrivateKey stores the key that is being rotated in for the chain (and its mnemonic, if any), without changing the active key
+func (cs *ChainStorage) SetPendingPrivateKey(privKey cryptotypes.PrivKey, mnemonic string, passphrase string) error {
+	if err := cs.parent.setEncryptedMnemonic(string(cs.chainKey(pendingMnemonicKey)), mnemonic, passphrase); err != nil {
+		return err
+	}
+	return cs.parent.setEncryptedKey(string(cs.chainKey(pendingPrivateKeyKey)), privKey, passphrase)
+}
+
+// PromotePendingPrivateKey makes the pending key the signing key of the chain and finishes the key rotation
+func (cs *ChainStorage) PromotePendingPrivateKey(passphrase string) (cryptotypes.PrivKey, error) {
+	privKey, ok, err := cs.PendingPrivateKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		panic("no pending private key to promote")
+	}
+
+	soloMachineStorage := cs.parent.getSoloMachineStorage()
+	soloMachineStorage.Set(cs.chainKey(privateKeyKey), soloMachineStorage.Get(cs.chainKey(pendingPrivateKeyKey)))
+	soloMachineStorage.Delete(cs.chainKey(pendingPrivateKeyKey))
+	if soloMachineStorage.Has(cs.chainKey(pendingMnemonicKey)) {
+		soloMachineStorage.Set(cs.chainKey(mnemonicKey), soloMachineStorage.Get(cs.chainKey(pendingMnemonicKey)))
+		soloMachineStorage.Delete(cs.chainKey(pendingMnemonicKey))
+	} else {
+		soloMachineStorage.Delete(cs.chainKey(mnemonicKey))
+	}
+	cs.parent.Commit()
+
+	return privKey, nil
+}
+
+func (cs *ChainStorage) chainKey(key string) []byte {
+	return []byte(chainKeysPrefix + cs.chainName + "/" + key)
+}
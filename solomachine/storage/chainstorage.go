@@ -11,12 +11,15 @@ import (
 	tmclient "github.com/cosmos/ibc-go/v8/modules/light-clients/07-tendermint"
 	"go.uber.org/zap"
 	"math/rand/v2"
+	"strings"
 )
 
 const (
 	lightClientPrefix = "light-clients"
+	packetsPrefix     = "packets"
 
 	diversifierKey              = "diversifier"
+	pendingDiversifierKey       = "pending-diversifier"
 	counterpartyClientIDKey     = "counterparty-client-id"
 	counterpartyConnectionIDKey = "counterparty-connection-id"
 	clientIDKey                 = "client-id"
@@ -24,14 +27,21 @@ const (
 
 	ics20ChannelKey             = "ics20-channel"
 	counterpartyICS20ChannelKey = "counterparty-ics20-channel"
+	// The channels initialized on the chain that the solo machine has not answered yet, as channel-inits/<port>/<counterparty port>
+	channelInitsPrefix = "channel-inits/"
+	// The public key of the pending shared key, once it is installed on the counterparty light client during a key rotation
+	installedPendingKeyKey = "installed-pending-key"
+	// The chains with a counterparty light client are listed in the solo machine store, as client-chains/<chain-name>
+	clientChainsPrefix = "client-chains/"
 )
 
 var _ exported.ClientStoreProvider = &ChainStorage{}
 
 type ChainStorage struct {
-	parent *Storage
-	logger *zap.Logger
-	store  *prefix.Store
+	parent    *Storage
+	logger    *zap.Logger
+	store     *prefix.Store
+	chainName string
 
 	diversifier              string
 	counterpartyClientID     string
@@ -44,18 +54,25 @@ type ChainStorage struct {
 	tmLightClientModule tmclient.LightClientModule
 }
 
+// ValidateChainName checks that the chain name can be used in the storage keys, where it is followed by a slash
+// (e.g. chain-keys/<chain-name>/<key>), so a chain name with a slash could share keys with another chain.
+func ValidateChainName(chainName string) error {
+	if strings.TrimSpace(chainName) == "" {
+		return fmt.Errorf("chain name cannot be empty")
+	}
+	if strings.Contains(chainName, "/") {
+		return fmt.Errorf("chain name %s cannot contain a /", chainName)
+	}
+
+	return nil
+}
+
 func (s *Storage) GetChainStorage(chainName string) *ChainStorage {
 	rootChainsStore := s.store.GetCommitKVStore(s.rootChainsStoreKey)
 	chainStore := prefix.NewStore(rootChainsStore, []byte(chainName))
 
 	if !chainStore.Has([]byte(diversifierKey)) {
-		charset := "abcdefghijklmnopqrstuvwxyz"
-		randomBytes := make([]byte, 15)
-		for i := range randomBytes {
-			randomBytes[i] = charset[rand.IntN(len(charset))]
-		}
-
-		chainStore.Set([]byte(diversifierKey), randomBytes)
+		chainStore.Set([]byte(diversifierKey), []byte(NewDiversifier()))
 	}
 
 	diversifier := string(chainStore.Get([]byte(diversifierKey)))
@@ -97,9 +114,10 @@ func (s *Storage) GetChainStorage(chainName string) *ChainStorage {
 	}
 
 	cs := &ChainStorage{
-		parent: s,
-		logger: s.logger,
-		store:  &chainStore,
+		parent:    s,
+		logger:    s.logger,
+		store:     &chainStore,
+		chainName: chainName,
 
 		diversifier:              diversifier,
 		counterpartyClientID:     counterpartyClientID,
@@ -120,10 +138,50 @@ func (s *Storage) GetChainStorage(chainName string) *ChainStorage {
 	return cs
 }
 
+// NewDiversifier generates a new random diversifier
+func NewDiversifier() string {
+	charset := "abcdefghijklmnopqrstuvwxyz"
+	randomBytes := make([]byte, 15)
+	for i := range randomBytes {
+		randomBytes[i] = charset[rand.IntN(len(charset))]
+	}
+
+	return string(randomBytes)
+}
+
 func (cs *ChainStorage) Diversifier() string {
 	return cs.diversifier
 }
 
+// PendingDiversifier returns the diversifier that is being rotated in, if a diversifier rotation is in progress
+func (cs *ChainStorage) PendingDiversifier() (string, bool) {
+	bz := cs.store.Get([]byte(pendingDiversifierKey))
+	if bz == nil {
+		return "", false
+	}
+
+	return string(bz), true
+}
+
+// SetPendingDiversifier stores the diversifier that is being rotated in, without changing the active diversifier
+func (cs *ChainStorage) SetPendingDiversifier(diversifier string) {
+	cs.store.Set([]byte(pendingDiversifierKey), []byte(diversifier))
+	cs.parent.Commit()
+}
+
+// PromotePendingDiversifier replaces the active diversifier with the pending one (in a single commit)
+func (cs *ChainStorage) PromotePendingDiversifier() {
+	diversifier, ok := cs.PendingDiversifier()
+	if !ok {
+		panic("no pending diversifier to promote")
+	}
+
+	cs.store.Set([]byte(diversifierKey), []byte(diversifier))
+	cs.store.Delete([]byte(pendingDiversifierKey))
+	cs.diversifier = diversifier
+	cs.parent.Commit()
+}
+
 func (cs *ChainStorage) ClientID() string {
 	return cs.clientID
 }
@@ -140,10 +198,36 @@ func (cs *ChainStorage) setClientID(clientID string) {
 
 func (cs *ChainStorage) SetCounterPartyClientID(clientID string) {
 	cs.store.Set([]byte(counterpartyClientIDKey), []byte(clientID))
+	cs.parent.getSoloMachineStorage().Set([]byte(clientChainsPrefix+cs.chainName), []byte{byte(1)})
 	cs.counterpartyClientID = clientID
 	cs.parent.Commit()
 }
 
+// ClientChainNames returns the names of the chains the solo machine has created a counterparty light client on, in order
+// Chains set up before the list was kept are not in it.
+func (s *Storage) ClientChainNames() []string {
+	iterator := storetypes.KVStorePrefixIterator(s.getSoloMachineStorage(), []byte(clientChainsPrefix))
+	defer iterator.Close()
+
+	var chainNames []string
+	for ; iterator.Valid(); iterator.Next() {
+		chainNames = append(chainNames, string(iterator.Key()[len(clientChainsPrefix):]))
+	}
+
+	return chainNames
+}
+
+// InstalledPendingKey returns the public key (bytes) of the pending shared key, if it has been installed on the counterparty light client
+func (cs *ChainStorage) InstalledPendingKey() []byte {
+	return cs.store.Get([]byte(installedPendingKeyKey))
+}
+
+// SetInstalledPendingKey records that the counterparty light client has the pending shared key, which the chain is signed with from now on
+func (cs *ChainStorage) SetInstalledPendingKey(pubKey []byte) {
+	cs.store.Set([]byte(installedPendingKeyKey), pubKey)
+	cs.parent.Commit()
+}
+
 // ClientStore implements exported.ClientStoreProvider
 // Is used by ibc light client module
 func (cs *ChainStorage) ClientStore(ctx sdk.Context, clientID string) storetypes.KVStore {
@@ -151,8 +235,14 @@ func (cs *ChainStorage) ClientStore(ctx sdk.Context, clientID string) storetypes
 	return prefix.NewStore(lightClientStore, []byte(clientID))
 }
 
+// LightClientExists returns true if the light client has been created
+// A recovered chain has a client id, but no light client until it has been created again.
 func (cs *ChainStorage) LightClientExists() bool {
-	return cs.clientID != ""
+	if cs.clientID == "" {
+		return false
+	}
+
+	return cs.ClientStore(sdk.Context{}, cs.clientID).Has(host.ClientStateKey())
 }
 
 func (cs *ChainStorage) CreateLightClient(ctx sdk.Context, clientState exported.ClientState, consensusState exported.ConsensusState) error {
@@ -170,10 +260,14 @@ func (cs *ChainStorage) CreateLightClient(ctx sdk.Context, clientState exported.
 		return err
 	}
 
-	nextClientSeq := cs.parent.nextLightClientNumber
-	defer cs.parent.incrementNextLightClientNumber()
+	// Keep the client id of a recovered chain, the connection on the chain refers to it
+	clientID := cs.clientID
+	if clientID == "" {
+		nextClientSeq := cs.parent.nextLightClientNumber
+		defer cs.parent.incrementNextLightClientNumber()
 
-	clientID := clienttypes.FormatClientIdentifier(exported.Tendermint, nextClientSeq)
+		clientID = clienttypes.FormatClientIdentifier(exported.Tendermint, nextClientSeq)
+	}
 	if err := cs.tmLightClientModule.Initialize(ctx, clientID, anyClientState.Value, anyConsensusState.Value); err != nil {
 		return err
 	}
@@ -223,3 +317,13 @@ func (cs *ChainStorage) GetLightConsensusState(height exported.Height) (*tmclien
 
 	return consensusState, nil
 }
+
+// VerifyMembership verifies a proof of the value at the path in the chain state at the given height, against the light client
+func (cs *ChainStorage) VerifyMembership(ctx sdk.Context, height exported.Height, proof []byte, path exported.Path, value []byte) error {
+	return cs.tmLightClientModule.VerifyMembership(ctx, cs.clientID, height, 0, 0, proof, path, value)
+}
+
+// VerifyNonMembership verifies a proof of absence of the path in the chain state at the given height, against the light client
+func (cs *ChainStorage) VerifyNonMembership(ctx sdk.Context, height exported.Height, proof []byte, path exported.Path) error {
+	return cs.tmLightClientModule.VerifyNonMembership(ctx, cs.clientID, height, 0, 0, proof, path)
+}
@@ -1,13 +1,28 @@
 package storage
 
-import channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+import (
+	"cmp"
+	storetypes "cosmossdk.io/store/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"slices"
+)
 
 func (cs *ChainStorage) CreateICS20Channel() string {
+	channelID := cs.CreateChannel(transfertypes.PortID)
+	cs.setICS20Channel(channelID)
+
+	return channelID
+}
+
+// CreateChannel allocates the next channel identifier and sets the next sequences of the channel on the port
+func (cs *ChainStorage) CreateChannel(portID string) string {
 	nextChannelSeq := cs.parent.nextChannelNumber
 	defer cs.parent.incrementNextChannelNumber()
 
 	channelID := channeltypes.FormatChannelIdentifier(nextChannelSeq)
-	cs.setICS20Channel(channelID)
+	cs.initNextSequences(portID, channelID)
 
 	return channelID
 }
@@ -39,3 +54,98 @@ func (cs *ChainStorage) SetCounterpartyICS20ChannelID(channelID string) {
 	cs.counterpartyICS20Channel = channelID
 	cs.parent.Commit()
 }
+
+// ChannelInit returns the channel the chain has initialized between the ports, that the solo machine has not stored its end of yet
+func (cs *ChainStorage) ChannelInit(portID string, counterpartyPortID string) (string, bool) {
+	bz := cs.store.Get(channelInitKey(portID, counterpartyPortID))
+	if bz == nil {
+		return "", false
+	}
+
+	return string(bz), true
+}
+
+// SetChannelInit stores the channel the chain has initialized between the ports, so the handshake can be resumed if it is interrupted
+func (cs *ChainStorage) SetChannelInit(portID string, counterpartyPortID string, counterpartyChannelID string) {
+	cs.store.Set(channelInitKey(portID, counterpartyPortID), []byte(counterpartyChannelID))
+	cs.parent.Commit()
+}
+
+// DeleteChannelInit forgets the channel the chain has initialized between the ports
+func (cs *ChainStorage) DeleteChannelInit(portID string, counterpartyPortID string) {
+	cs.store.Delete(channelInitKey(portID, counterpartyPortID))
+	cs.parent.Commit()
+}
+
+// channelInitKey is valid for all ports, since port identifiers cannot contain a slash
+func channelInitKey(portID string, counterpartyPortID string) []byte {
+	return []byte(channelInitsPrefix + portID + "/" + counterpartyPortID)
+}
+
+// Channel returns the solo machine end of a channel
+func (cs *ChainStorage) Channel(portID string, channelID string) (channeltypes.Channel, bool) {
+	bz := cs.store.Get(host.ChannelKey(portID, channelID))
+	if bz == nil {
+		return channeltypes.Channel{}, false
+	}
+
+	var channel channeltypes.Channel
+	cs.parent.cdc.MustUnmarshal(bz, &channel)
+
+	return channel, true
+}
+
+// SetChannel stores the solo machine end of a channel
+func (cs *ChainStorage) SetChannel(portID string, channelID string, channel channeltypes.Channel) {
+	cs.setChannel(portID, channelID, channel)
+	cs.parent.Commit()
+}
+
+// CloseChannel sets the solo machine end of a channel to CLOSED
+func (cs *ChainStorage) CloseChannel(portID string, channelID string) {
+	cs.closeChannel(portID, channelID)
+	cs.parent.Commit()
+}
+
+// closeChannel does the same as CloseChannel, without committing
+func (cs *ChainStorage) closeChannel(portID string, channelID string) {
+	if channel, ok := cs.Channel(portID, channelID); ok {
+		channel.State = channeltypes.CLOSED
+		cs.setChannel(portID, channelID, channel)
+	}
+}
+
+// setChannel does the same as SetChannel, without committing
+func (cs *ChainStorage) setChannel(portID string, channelID string, channel channeltypes.Channel) {
+	cs.store.Set(host.ChannelKey(portID, channelID), cs.parent.cdc.MustMarshal(&channel))
+}
+
+// Channels returns the solo machine ends of all the channels, in any state, ordered by port and channel
+func (cs *ChainStorage) Channels() ([]channeltypes.IdentifiedChannel, error) {
+	iterator := storetypes.KVStorePrefixIterator(cs.store, []byte(host.KeyChannelEndPrefix+"/"))
+	defer iterator.Close()
+
+	var channels []channeltypes.IdentifiedChannel
+	for ; iterator.Valid(); iterator.Next() {
+		portID, channelID, err := host.ParseChannelPath(string(iterator.Key()))
+		if err != nil {
+			return nil, err
+		}
+
+		var channel channeltypes.Channel
+		cs.parent.cdc.MustUnmarshal(iterator.Value(), &channel)
+		channels = append(channels, channeltypes.NewIdentifiedChannel(portID, channelID, channel))
+	}
+
+	// The keys are ordered as strings, not by channel sequence
+	slices.SortFunc(channels, func(a, b channeltypes.IdentifiedChannel) int {
+		if a.PortId != b.PortId {
+			return cmp.Compare(a.PortId, b.PortId)
+		}
+		aSequence, _ := channeltypes.ParseChannelSequence(a.ChannelId)
+		bSequence, _ := channeltypes.ParseChannelSequence(b.ChannelId)
+		return cmp.Compare(aSequence, bSequence)
+	})
+
+	return channels, nil
+}
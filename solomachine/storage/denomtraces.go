@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+)
+
+// denomTracesPrefix keeps the denom traces of the vouchers in the ledger, by the hash in their ibc/<hash> denom
+const denomTracesPrefix = "denom-traces/"
+
+func (s *Storage) denomTracesStore() prefix.Store {
+	return prefix.NewStore(s.getSoloMachineStorage(), []byte(denomTracesPrefix))
+}
+
+// HasDenomTrace checks if a denom trace with the hash exists
+func (s *Storage) HasDenomTrace(hash cmtbytes.HexBytes) bool {
+	return s.denomTracesStore().Has(hash)
+}
+
+// DenomTrace returns the denom trace with the hash
+func (s *Storage) DenomTrace(hash cmtbytes.HexBytes) (transfertypes.DenomTrace, bool) {
+	bz := s.denomTracesStore().Get(hash)
+	if bz == nil {
+		return transfertypes.DenomTrace{}, false
+	}
+
+	var denomTrace transfertypes.DenomTrace
+	s.cdc.MustUnmarshal(bz, &denomTrace)
+
+	return denomTrace, true
+}
+
+// DenomTraces returns all the denom traces, sorted by their full denom path
+func (s *Storage) DenomTraces() transfertypes.Traces {
+	iterator := storetypes.KVStorePrefixIterator(s.denomTracesStore(), nil)
+	defer iterator.Close()
+
+	var denomTraces transfertypes.Traces
+	for ; iterator.Valid(); iterator.Next() {
+		var denomTrace transfertypes.DenomTrace
+		s.cdc.MustUnmarshal(iterator.Value(), &denomTrace)
+		denomTraces = append(denomTraces, denomTrace)
+	}
+
+	return denomTraces.Sort()
+}
+
+// SetDenomTrace stores the denom trace (not committed, like the ledger changes it belongs to)
+func (s *Storage) SetDenomTrace(denomTrace transfertypes.DenomTrace) {
+	s.denomTracesStore().Set(denomTrace.Hash(), s.cdc.MustMarshal(&denomTrace))
+}
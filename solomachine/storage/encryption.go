@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// encryptedKeyPrefix marks key bytes in storage as encrypted (keys stored before encryption was added are plain key bytes)
+	// The encrypted content is the proto encoded private key (as an Any), so it can be any key algorithm
+	encryptedKeyPrefix = []byte("sm-encrypted-v2:")
+	// encryptedKeyPrefixV1 marks encrypted keys that contain raw secp256k1 key bytes (before other key algorithms were supported)
+	encryptedKeyPrefixV1 = []byte("sm-encrypted-v1:")
+)
+
+const (
+	saltLength = 16
+
+	// scrypt parameters as recommended for interactive logins in 2017 (https://pkg.go.dev/golang.org/x/crypto/scrypt)
+	scryptN      = 32768
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// encryptKeyBytes encrypts key bytes with AES-256-GCM, with the encryption key derived from the passphrase with scrypt
+// The result is the prefix, followed by the salt, the nonce and the ciphertext
+// An empty passphrase is rejected, since it would only look like the key is protected
+func encryptKeyBytes(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("the passphrase for the solo machine key cannot be empty")
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newKeyAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, encryptedKeyPrefix...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, encryptedKeyPrefix), nil
+}
+
+// decryptKeyBytes decrypts key bytes encrypted with encryptKeyBytes, it returns the prefix the key was encrypted with together with the plaintext
+func decryptKeyBytes(encrypted []byte, passphrase string) ([]byte, []byte, error) {
+	var prefix []byte
+	switch {
+	case bytes.HasPrefix(encrypted, encryptedKeyPrefix):
+		prefix = encryptedKeyPrefix
+	case bytes.HasPrefix(encrypted, encryptedKeyPrefixV1):
+		prefix = encryptedKeyPrefixV1
+	default:
+		return nil, nil, fmt.Errorf("key is not encrypted")
+	}
+	encrypted = encrypted[len(prefix):]
+
+	if len(encrypted) < saltLength {
+		return nil, nil, fmt.Errorf("encrypted key is too short")
+	}
+	salt, encrypted := encrypted[:saltLength], encrypted[saltLength:]
+
+	aead, err := newKeyAEAD(passphrase, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(encrypted) < aead.NonceSize() {
+		return nil, nil, fmt.Errorf("encrypted key is too short")
+	}
+	nonce, ciphertext := encrypted[:aead.NonceSize()], encrypted[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, prefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt the solo machine key, wrong passphrase?")
+	}
+
+	return prefix, plaintext, nil
+}
+
+func isEncryptedKeyBytes(bz []byte) bool {
+	return bytes.HasPrefix(bz, encryptedKeyPrefix) || bytes.HasPrefix(bz, encryptedKeyPrefixV1)
+}
+
+func newKeyAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
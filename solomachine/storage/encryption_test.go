@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEncryptKeyBytes(t *testing.T) {
+	encrypted, err := encryptKeyBytes([]byte("secret key"), "passphrase")
+	require.NoError(t, err)
+	require.True(t, isEncryptedKeyBytes(encrypted))
+	require.NotContains(t, string(encrypted), "secret key")
+
+	prefix, plaintext, err := decryptKeyBytes(encrypted, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, encryptedKeyPrefix, prefix)
+	require.Equal(t, []byte("secret key"), plaintext)
+
+	_, _, err = decryptKeyBytes(encrypted, "wrong passphrase")
+	require.Error(t, err)
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, _, err = decryptKeyBytes(tampered, "passphrase")
+	require.Error(t, err)
+
+	_, _, err = decryptKeyBytes([]byte("secret key"), "passphrase")
+	require.Error(t, err, "plain key bytes are not decrypted")
+}
+
+func TestEncryptKeyBytesRejectsEmptyPassphrase(t *testing.T) {
+	_, err := encryptKeyBytes([]byte("secret key"), "")
+	require.Error(t, err)
+}
+
+func TestLocalPrivateKeyEncryption(t *testing.T) {
+	s := newTestStorage(t)
+	privKey := secp256k1.GenPrivKey()
+
+	require.Error(t, s.CreateLocalPrivateKey(privKey, "", ""), "an empty passphrase is rejected")
+	require.False(t, s.HasLocalPrivateKey())
+
+	require.NoError(t, s.CreateLocalPrivateKey(privKey, "", "passphrase"))
+	stored, err := s.LocalPrivateKey("passphrase")
+	require.NoError(t, err)
+	require.True(t, privKey.Equals(stored))
+	_, err = s.LocalPrivateKey("wrong passphrase")
+	require.Error(t, err)
+
+	require.Error(t, s.ChangeLocalKeyPassphrase("passphrase", ""), "an empty passphrase is rejected")
+	require.NoError(t, s.ChangeLocalKeyPassphrase("passphrase", "new passphrase"))
+	_, err = s.LocalPrivateKey("passphrase")
+	require.Error(t, err)
+	stored, err = s.LocalPrivateKey("new passphrase")
+	require.NoError(t, err)
+	require.True(t, privKey.Equals(stored))
+}
+
+func TestUnencryptedLocalPrivateKeyIsEncryptedWhenRead(t *testing.T) {
+	s := newTestStorage(t)
+	privKey := secp256k1.GenPrivKey()
+	s.getSoloMachineStorage().Set([]byte(privateKeyKey), privKey.Key)
+	s.Commit()
+
+	_, err := s.LocalPrivateKey("")
+	require.Error(t, err, "an unencrypted key is not encrypted with an empty passphrase")
+
+	stored, err := s.LocalPrivateKey("passphrase")
+	require.NoError(t, err)
+	require.True(t, privKey.Equals(stored))
+	require.True(t, isEncryptedKeyBytes(s.getSoloMachineStorage().Get([]byte(privateKeyKey))))
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"cosmossdk.io/store/prefix"
+)
+
+const (
+	// interchainAccountChannelsPrefix keeps the channel of each interchain account controller port
+	interchainAccountChannelsPrefix = "ica-channels/"
+	// interchainAccountAddressesPrefix keeps the address on the chain of each interchain account controller port
+	interchainAccountAddressesPrefix = "ica-addresses/"
+)
+
+func (cs *ChainStorage) interchainAccountChannelsStore() prefix.Store {
+	return prefix.NewStore(cs.store, []byte(interchainAccountChannelsPrefix))
+}
+
+func (cs *ChainStorage) interchainAccountAddressesStore() prefix.Store {
+	return prefix.NewStore(cs.store, []byte(interchainAccountAddressesPrefix))
+}
+
+// InterchainAccountChannelID returns the channel of the interchain account controller port, which might still be in the handshake
+func (cs *ChainStorage) InterchainAccountChannelID(portID string) (string, bool) {
+	bz := cs.interchainAccountChannelsStore().Get([]byte(portID))
+	if bz == nil {
+		return "", false
+	}
+
+	return string(bz), true
+}
+
+// SetInterchainAccountChannelID stores the channel of the interchain account controller port (not committed, like the channel end it belongs to)
+func (cs *ChainStorage) SetInterchainAccountChannelID(portID string, channelID string) {
+	cs.interchainAccountChannelsStore().Set([]byte(portID), []byte(channelID))
+}
+
+// InterchainAccountAddress returns the address of the interchain account the chain registered for the controller port
+func (cs *ChainStorage) InterchainAccountAddress(portID string) (string, bool) {
+	bz := cs.interchainAccountAddressesStore().Get([]byte(portID))
+	if bz == nil {
+		return "", false
+	}
+
+	return string(bz), true
+}
+
+// SetInterchainAccountAddress stores the address of the interchain account (not committed, like the channel end it belongs to)
+func (cs *ChainStorage) SetInterchainAccountAddress(portID string, address string) {
+	cs.interchainAccountAddressesStore().Set([]byte(portID), []byte(address))
+}
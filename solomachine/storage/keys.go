@@ -0,0 +1,253 @@
+package storage
+
+import (
+	"bytes"
+	storetypes "cosmossdk.io/store/types"
+	"fmt"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"go.uber.org/zap"
+)
+
+// HasLocalPrivateKey returns true if the local signer key has been created
+func (s *Storage) HasLocalPrivateKey() bool {
+	return s.getSoloMachineStorage().Has([]byte(privateKeyKey))
+}
+
+// LocalKeyAlgorithm returns the algorithm of the local signer key (keys from before the algorithm was recorded are always secp256k1)
+func (s *Storage) LocalKeyAlgorithm() string {
+	keyAlgorithmBz := s.getSoloMachineStorage().Get([]byte(keyAlgorithmKey))
+	if keyAlgorithmBz == nil {
+		return secp256k1.PrivKeyName
+	}
+
+	return string(keyAlgorithmBz)
+}
+
+// CreateLocalPrivateKey stores a new local signer key, encrypted with the passphrase, and records its algorithm
+// The mnemonic the key was derived from is stored (encrypted) as well, so it can be exported later. It is empty for imported keys.
+func (s *Storage) CreateLocalPrivateKey(privKey cryptotypes.PrivKey, mnemonic string, passphrase string) error {
+	if s.HasLocalPrivateKey() {
+		return fmt.Errorf("local solo machine key already exists")
+	}
+
+	s.getSoloMachineStorage().Set([]byte(keyAlgorithmKey), []byte(privKey.Type()))
+	if err := s.setEncryptedMnemonic(mnemonicKey, mnemonic, passphrase); err != nil {
+		return err
+	}
+	return s.setEncryptedKey(privateKeyKey, privKey, passphrase)
+}
+
+// LocalMnemonic returns the mnemonic the local signer key was derived from, if it was created from one
+func (s *Storage) LocalMnemonic(passphrase string) (string, bool, error) {
+	return s.getEncryptedMnemonic(mnemonicKey, passphrase)
+}
+
+// LocalPrivateKey returns the private key used by the local signer, decrypted with the passphrase
+// Keys stored unencrypted by older versions are encrypted with the passphrase when they are read.
+func (s *Storage) LocalPrivateKey(passphrase string) (cryptotypes.PrivKey, error) {
+	return s.getEncryptedKey(privateKeyKey, passphrase)
+}
+
+// PendingLocalPrivateKey returns the key that is being rotated in, if a key rotation is in progress
+func (s *Storage) PendingLocalPrivateKey(passphrase string) (cryptotypes.PrivKey, bool, error) {
+	if !s.getSoloMachineStorage().Has([]byte(pendingPrivateKeyKey)) {
+		return nil, false, nil
+	}
+
+	privKey, err := s.getEncryptedKey(pendingPrivateKeyKey, passphrase)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return privKey, true, nil
+}
+
+// SetPendingLocalPrivateKey stores the key that is being rotated in (and its mnemonic, if any), without changing the active key
+func (s *Storage) SetPendingLocalPrivateKey(privKey cryptotypes.PrivKey, mnemonic string, passphrase string) error {
+	if err := s.setEncryptedMnemonic(pendingMnemonicKey, mnemonic, passphrase); err != nil {
+		return err
+	}
+	return s.setEncryptedKey(pendingPrivateKeyKey, privKey, passphrase)
+}
+
+// PromotePendingLocalPrivateKey replaces the active key with the pending key and finishes the key rotation
+func (s *Storage) PromotePendingLocalPrivateKey(passphrase string) (cryptotypes.PrivKey, error) {
+	privKey, ok, err := s.PendingLocalPrivateKey(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		panic("no pending private key to promote")
+	}
+
+	soloMachineStorage := s.getSoloMachineStorage()
+	soloMachineStorage.Set([]byte(privateKeyKey), soloMachineStorage.Get([]byte(pendingPrivateKeyKey)))
+	soloMachineStorage.Set([]byte(keyAlgorithmKey), []byte(privKey.Type()))
+	soloMachineStorage.Delete([]byte(pendingPrivateKeyKey))
+	if soloMachineStorage.Has([]byte(pendingMnemonicKey)) {
+		soloMachineStorage.Set([]byte(mnemonicKey), soloMachineStorage.Get([]byte(pendingMnemonicKey)))
+		soloMachineStorage.Delete([]byte(pendingMnemonicKey))
+	} else {
+		soloMachineStorage.Delete([]byte(mnemonicKey))
+	}
+	s.Commit()
+
+	return privKey, nil
+}
+
+// ChangeLocalKeyPassphrase re-encrypts the local signer key and mnemonic (and the pending and per-chain ones, if any) with a new passphrase
+func (s *Storage) ChangeLocalKeyPassphrase(oldPassphrase string, newPassphrase string) error {
+	if !s.HasLocalPrivateKey() {
+		return fmt.Errorf("there is no local solo machine key")
+	}
+
+	soloMachineStorage := s.getSoloMachineStorage()
+	for _, key := range []string{privateKeyKey, pendingPrivateKeyKey} {
+		if !soloMachineStorage.Has([]byte(key)) {
+			continue
+		}
+
+		privKey, err := s.getEncryptedKey(key, oldPassphrase)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := s.encryptKey(privKey, newPassphrase)
+		if err != nil {
+			return err
+		}
+		soloMachineStorage.Set([]byte(key), encrypted)
+	}
+
+	for _, key := range []string{mnemonicKey, pendingMnemonicKey} {
+		if !soloMachineStorage.Has([]byte(key)) {
+			continue
+		}
+
+		_, mnemonic, err := decryptKeyBytes(soloMachineStorage.Get([]byte(key)), oldPassphrase)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := encryptKeyBytes(mnemonic, newPassphrase)
+		if err != nil {
+			return err
+		}
+		soloMachineStorage.Set([]byte(key), encrypted)
+	}
+
+	// The per-chain keys and mnemonics are always stored in the current format, so they can be re-encrypted as is
+	var chainKeys []string
+	iterator := storetypes.KVStorePrefixIterator(soloMachineStorage, []byte(chainKeysPrefix))
+	for ; iterator.Valid(); iterator.Next() {
+		chainKeys = append(chainKeys, string(iterator.Key()))
+	}
+	if err := iterator.Close(); err != nil {
+		return err
+	}
+	for _, key := range chainKeys {
+		_, plaintext, err := decryptKeyBytes(soloMachineStorage.Get([]byte(key)), oldPassphrase)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := encryptKeyBytes(plaintext, newPassphrase)
+		if err != nil {
+			return err
+		}
+		soloMachineStorage.Set([]byte(key), encrypted)
+	}
+
+	// Only commit once all the keys (and mnemonics) have been re-encrypted
+	s.Commit()
+
+	return nil
+}
+
+// setEncryptedMnemonic stores the mnemonic encrypted with the passphrase, or removes the stored one if the mnemonic is empty
+// It does not commit, the mnemonic is always stored together with its key.
+func (s *Storage) setEncryptedMnemonic(key string, mnemonic string, passphrase string) error {
+	if mnemonic == "" {
+		s.getSoloMachineStorage().Delete([]byte(key))
+		return nil
+	}
+
+	encrypted, err := encryptKeyBytes([]byte(mnemonic), passphrase)
+	if err != nil {
+		return err
+	}
+	s.getSoloMachineStorage().Set([]byte(key), encrypted)
+
+	return nil
+}
+
+func (s *Storage) getEncryptedMnemonic(key string, passphrase string) (string, bool, error) {
+	bz := s.getSoloMachineStorage().Get([]byte(key))
+	if bz == nil {
+		return "", false, nil
+	}
+
+	_, mnemonic, err := decryptKeyBytes(bz, passphrase)
+	if err != nil {
+		return "", false, err
+	}
+
+	return string(mnemonic), true, nil
+}
+
+func (s *Storage) setEncryptedKey(key string, privKey cryptotypes.PrivKey, passphrase string) error {
+	encrypted, err := s.encryptKey(privKey, passphrase)
+	if err != nil {
+		return err
+	}
+
+	s.getSoloMachineStorage().Set([]byte(key), encrypted)
+	s.Commit()
+
+	return nil
+}
+
+func (s *Storage) getEncryptedKey(key string, passphrase string) (cryptotypes.PrivKey, error) {
+	bz := s.getSoloMachineStorage().Get([]byte(key))
+	if bz == nil {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+
+	if !isEncryptedKeyBytes(bz) {
+		s.logger.Warn("Found unencrypted solo machine key, encrypting it with the passphrase", zap.String("key", key))
+		privKey := &secp256k1.PrivKey{Key: bz}
+		if err := s.setEncryptedKey(key, privKey, passphrase); err != nil {
+			return nil, err
+		}
+
+		return privKey, nil
+	}
+
+	prefix, plaintext, err := decryptKeyBytes(bz, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(prefix, encryptedKeyPrefixV1) {
+		return &secp256k1.PrivKey{
+			Key: plaintext,
+		}, nil
+	}
+
+	var privKey cryptotypes.PrivKey
+	if err := s.cdc.UnmarshalInterface(plaintext, &privKey); err != nil {
+		return nil, err
+	}
+
+	return privKey, nil
+}
+
+func (s *Storage) encryptKey(privKey cryptotypes.PrivKey, passphrase string) ([]byte, error) {
+	plaintext, err := s.cdc.MarshalInterface(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return encryptKeyBytes(plaintext, passphrase)
+}
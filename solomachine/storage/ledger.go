@@ -0,0 +1,201 @@
+package storage
+
+import (
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+	"errors"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/address"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"strings"
+)
+
+// balancesPrefix keeps the balances of the local accounts, as balances/<length prefixed account><denom>, the same way as the bank module
+const balancesPrefix = "balances/"
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// EscrowAccount is the account tokens sent out over an ICS20 channel are escrowed in
+// Regular accounts cannot contain a "/", so it cannot clash with them.
+func EscrowAccount(portID string, channelID string) string {
+	return fmt.Sprintf("escrow/%s/%s", portID, channelID)
+}
+
+// ValidateAccount checks that the account name can be used for a local account
+func ValidateAccount(account string) error {
+	if strings.TrimSpace(account) == "" {
+		return fmt.Errorf("account cannot be empty")
+	}
+	if strings.Contains(account, "/") {
+		return fmt.Errorf("account %s cannot contain a /", account)
+	}
+	if len(account) > address.MaxAddrLen {
+		return fmt.Errorf("account %s is longer than %d characters", account, address.MaxAddrLen)
+	}
+
+	return nil
+}
+
+func (s *Storage) accountBalancesStore(account string) prefix.Store {
+	balancesStore := prefix.NewStore(s.getSoloMachineStorage(), []byte(balancesPrefix))
+	return prefix.NewStore(balancesStore, address.MustLengthPrefix([]byte(account)))
+}
+
+// Balance returns the balance of the denom for the account (zero if it has none)
+func (s *Storage) Balance(account string, denom string) sdkmath.Int {
+	bz := s.accountBalancesStore(account).Get([]byte(denom))
+	if bz == nil {
+		return sdkmath.ZeroInt()
+	}
+
+	var amount sdkmath.Int
+	if err := amount.Unmarshal(bz); err != nil {
+		panic(err)
+	}
+
+	return amount
+}
+
+// Balances returns all the balances of the account
+func (s *Storage) Balances(account string) sdk.Coins {
+	iterator := storetypes.KVStorePrefixIterator(s.accountBalancesStore(account), nil)
+	defer iterator.Close()
+
+	coins := sdk.NewCoins()
+	for ; iterator.Valid(); iterator.Next() {
+		var amount sdkmath.Int
+		if err := amount.Unmarshal(iterator.Value()); err != nil {
+			panic(err)
+		}
+		coins = coins.Add(sdk.NewCoin(string(iterator.Key()), amount))
+	}
+
+	return coins
+}
+
+// The ledger changes below are not committed on their own. They are committed together with the packet state they belong to,
+// or with Commit. All checks are done before anything is written, so a failed change leaves nothing behind to be committed.
+
+// MintCoins adds the coin to the balance of the account
+func (s *Storage) MintCoins(account string, coin sdk.Coin) error {
+	if err := coin.Validate(); err != nil {
+		return err
+	}
+
+	s.setBalance(account, coin.Denom, s.Balance(account, coin.Denom).Add(coin.Amount))
+
+	return nil
+}
+
+// BurnCoins removes the coin from the balance of the account
+func (s *Storage) BurnCoins(account string, coin sdk.Coin) error {
+	if err := coin.Validate(); err != nil {
+		return err
+	}
+
+	balance := s.Balance(account, coin.Denom)
+	if balance.LT(coin.Amount) {
+		return fmt.Errorf("%w: %s has %s%s, needs %s", ErrInsufficientFunds, account, balance, coin.Denom, coin)
+	}
+
+	s.setBalance(account, coin.Denom, balance.Sub(coin.Amount))
+
+	return nil
+}
+
+// SendCoins moves the coin from one account to another
+func (s *Storage) SendCoins(from string, to string, coin sdk.Coin) error {
+	if err := s.BurnCoins(from, coin); err != nil {
+		return err
+	}
+
+	return s.MintCoins(to, coin)
+}
+
+func (s *Storage) setBalance(account string, denom string, amount sdkmath.Int) {
+	store := s.accountBalancesStore(account)
+	if amount.IsZero() {
+		store.Delete([]byte(denom))
+		return
+	}
+
+	bz, err := amount.Marshal()
+	if err != nil {
+		panic(err)
+	}
+	store.Set([]byte(denom), bz)
+}
+
+// AccountBalance is the balance of an account in a ledger backup
+type AccountBalance struct {
+	Account string    `json:"account"`
+	Coins   sdk.Coins `json:"coins"`
+}
+
+// LedgerBackup is the state of the built-in ledger: the balances of all the accounts (the escrow accounts too, since the escrowed
+// tokens are still out on the chains) and the denom traces of the vouchers
+type LedgerBackup struct {
+	Balances    []AccountBalance     `json:"balances,omitempty"`
+	DenomTraces transfertypes.Traces `json:"denom_traces,omitempty"`
+}
+
+// LedgerBackup returns the balances of all the accounts, ordered by account, and all the denom traces
+func (s *Storage) LedgerBackup() LedgerBackup {
+	iterator := storetypes.KVStorePrefixIterator(s.getSoloMachineStorage(), []byte(balancesPrefix))
+	defer iterator.Close()
+
+	var backup LedgerBackup
+	for ; iterator.Valid(); iterator.Next() {
+		// The keys are balances/<length prefixed account><denom>
+		key := iterator.Key()[len(balancesPrefix):]
+		account := string(key[1 : 1+int(key[0])])
+		denom := string(key[1+int(key[0]):])
+
+		var amount sdkmath.Int
+		if err := amount.Unmarshal(iterator.Value()); err != nil {
+			panic(err)
+		}
+
+		if len(backup.Balances) == 0 || backup.Balances[len(backup.Balances)-1].Account != account {
+			backup.Balances = append(backup.Balances, AccountBalance{Account: account})
+		}
+		last := &backup.Balances[len(backup.Balances)-1]
+		last.Coins = last.Coins.Add(sdk.NewCoin(denom, amount))
+	}
+	backup.DenomTraces = s.DenomTraces()
+
+	return backup
+}
+
+// RestoreLedger sets the balances and denom traces from a backup, it can only be done into an empty ledger
+func (s *Storage) RestoreLedger(backup LedgerBackup) error {
+	iterator := storetypes.KVStorePrefixIterator(s.getSoloMachineStorage(), []byte(balancesPrefix))
+	empty := !iterator.Valid()
+	iterator.Close()
+	if !empty || len(s.DenomTraces()) != 0 {
+		return fmt.Errorf("the ledger is not empty, it can only be restored in a fresh home directory")
+	}
+
+	for _, balance := range backup.Balances {
+		if strings.TrimSpace(balance.Account) == "" {
+			return fmt.Errorf("the backup has a balance without an account")
+		}
+		if err := balance.Coins.Validate(); err != nil {
+			return fmt.Errorf("balance of %s: %w", balance.Account, err)
+		}
+		for _, coin := range balance.Coins {
+			s.setBalance(balance.Account, coin.Denom, coin.Amount)
+		}
+	}
+	for _, denomTrace := range backup.DenomTraces {
+		if err := denomTrace.Validate(); err != nil {
+			return err
+		}
+		s.SetDenomTrace(denomTrace)
+	}
+	s.Commit()
+
+	return nil
+}
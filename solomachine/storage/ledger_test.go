@@ -0,0 +1,59 @@
+package storage
+
+import (
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLedgerMintSendBurn(t *testing.T) {
+	s := newTestStorage(t)
+	escrow := EscrowAccount(transfertypes.PortID, "channel-0")
+
+	require.NoError(t, s.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	require.NoError(t, s.SendCoins("alice", escrow, sdk.NewInt64Coin("stake", 40)))
+	require.Equal(t, sdkmath.NewInt(60), s.Balance("alice", "stake"))
+	require.Equal(t, sdkmath.NewInt(40), s.Balance(escrow, "stake"))
+
+	// Refund from the escrow account
+	require.NoError(t, s.SendCoins(escrow, "alice", sdk.NewInt64Coin("stake", 40)))
+	require.Equal(t, sdkmath.NewInt(100), s.Balance("alice", "stake"))
+	require.True(t, s.Balance(escrow, "stake").IsZero())
+	require.Empty(t, s.Balances(escrow), "empty balances are removed")
+
+	require.NoError(t, s.BurnCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	require.True(t, s.Balances("alice").IsZero())
+}
+
+func TestLedgerInsufficientFunds(t *testing.T) {
+	s := newTestStorage(t)
+	require.NoError(t, s.MintCoins("alice", sdk.NewInt64Coin("stake", 10)))
+
+	require.ErrorIs(t, s.SendCoins("alice", "bob", sdk.NewInt64Coin("stake", 11)), ErrInsufficientFunds)
+	require.ErrorIs(t, s.BurnCoins("alice", sdk.NewInt64Coin("other", 1)), ErrInsufficientFunds)
+	require.Equal(t, sdkmath.NewInt(10), s.Balance("alice", "stake"), "a failed send changes nothing")
+	require.True(t, s.Balance("bob", "stake").IsZero())
+}
+
+func TestLedgerBackupRestore(t *testing.T) {
+	s := newTestStorage(t)
+	denomTrace := transfertypes.ParseDenomTrace("transfer/channel-0/uatom")
+	require.NoError(t, s.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	require.NoError(t, s.MintCoins("alice", sdk.NewInt64Coin(denomTrace.IBCDenom(), 5)))
+	require.NoError(t, s.SendCoins("alice", EscrowAccount(transfertypes.PortID, "channel-0"), sdk.NewInt64Coin("stake", 30)))
+	s.SetDenomTrace(denomTrace)
+	s.Commit()
+
+	backup := s.LedgerBackup()
+	require.Len(t, backup.Balances, 2)
+
+	restored := newTestStorage(t)
+	require.NoError(t, restored.RestoreLedger(backup))
+	require.Equal(t, s.Balances("alice"), restored.Balances("alice"))
+	require.Equal(t, s.Balances(EscrowAccount(transfertypes.PortID, "channel-0")), restored.Balances(EscrowAccount(transfertypes.PortID, "channel-0")))
+	require.Equal(t, s.DenomTraces(), restored.DenomTraces())
+
+	require.Error(t, restored.RestoreLedger(backup), "a ledger is only restored into an empty ledger")
+}
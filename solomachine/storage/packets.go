@@ -0,0 +1,328 @@
+package storage
+
+import (
+	"cmp"
+	"cosmossdk.io/store/prefix"
+	storetypes "cosmossdk.io/store/types"
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"slices"
+	"strconv"
+)
+
+const (
+	// Sent packets and the acknowledgements the chain wrote for them are kept next to the commitments, under their own prefixes
+	sentPacketsPrefix                  = "sent-packets/"
+	counterpartyAcknowledgementsPrefix = "counterparty-acks/"
+	timeoutsPrefix                     = "timeouts/"
+	// Send sequences reserved for packets that are sent later, e.g. after they have been signed offline
+	reservedSequencesPrefix = "reserved-sequences/"
+)
+
+// packetsStore holds the packet state of the solo machine side of the channels, under the same keys as the IBC store on a chain
+func (cs *ChainStorage) packetsStore() prefix.Store {
+	return prefix.NewStore(cs.store, []byte(packetsPrefix))
+}
+
+// NextSequenceSend returns the sequence of the next packet the solo machine sends on the channel
+func (cs *ChainStorage) NextSequenceSend(portID string, channelID string) uint64 {
+	return cs.nextSequence(host.NextSequenceSendKey(portID, channelID))
+}
+
+// NextSequenceRecv returns the sequence of the next packet the solo machine receives on the channel (only used for ordered channels)
+func (cs *ChainStorage) NextSequenceRecv(portID string, channelID string) uint64 {
+	return cs.nextSequence(host.NextSequenceRecvKey(portID, channelID))
+}
+
+// NextSequenceAck returns the sequence of the next acknowledgement the solo machine processes on the channel (only used for ordered channels)
+func (cs *ChainStorage) NextSequenceAck(portID string, channelID string) uint64 {
+	return cs.nextSequence(host.NextSequenceAckKey(portID, channelID))
+}
+
+func (cs *ChainStorage) SetNextSequenceRecv(portID string, channelID string, sequence uint64) {
+	cs.packetsStore().Set(host.NextSequenceRecvKey(portID, channelID), sdk.Uint64ToBigEndian(sequence))
+	cs.parent.Commit()
+}
+
+func (cs *ChainStorage) SetNextSequenceAck(portID string, channelID string, sequence uint64) {
+	cs.packetsStore().Set(host.NextSequenceAckKey(portID, channelID), sdk.Uint64ToBigEndian(sequence))
+	cs.parent.Commit()
+}
+
+// nextSequence returns the sequence stored under the key, sequences start at 1 like on a chain
+func (cs *ChainStorage) nextSequence(key []byte) uint64 {
+	bz := cs.packetsStore().Get(key)
+	if bz == nil {
+		return 1
+	}
+
+	return sdk.BigEndianToUint64(bz)
+}
+
+// initNextSequences sets all the next sequences of a new channel to 1 (without committing)
+func (cs *ChainStorage) initNextSequences(portID string, channelID string) {
+	store := cs.packetsStore()
+	store.Set(host.NextSequenceSendKey(portID, channelID), sdk.Uint64ToBigEndian(1))
+	store.Set(host.NextSequenceRecvKey(portID, channelID), sdk.Uint64ToBigEndian(1))
+	store.Set(host.NextSequenceAckKey(portID, channelID), sdk.Uint64ToBigEndian(1))
+}
+
+// orderedChannel returns true if the solo machine end of the channel is ordered (channels without a stored end are ICS20 channels, which are unordered)
+func (cs *ChainStorage) orderedChannel(portID string, channelID string) bool {
+	channel, ok := cs.Channel(portID, channelID)
+	return ok && channel.Ordering == channeltypes.ORDERED
+}
+
+// HasPacketReceipt returns true if the solo machine has received the packet
+func (cs *ChainStorage) HasPacketReceipt(portID string, channelID string, sequence uint64) bool {
+	return cs.packetsStore().Has(host.PacketReceiptKey(portID, channelID, sequence))
+}
+
+// PacketAcknowledgement returns the acknowledgement the solo machine wrote for a received packet
+func (cs *ChainStorage) PacketAcknowledgement(portID string, channelID string, sequence uint64) ([]byte, bool) {
+	bz := cs.packetsStore().Get(host.PacketAcknowledgementKey(portID, channelID, sequence))
+	if bz == nil {
+		return nil, false
+	}
+
+	return bz, true
+}
+
+// ReceivePacket records the acknowledgement of a received packet, together with its receipt on an unordered channel
+// or the next receive sequence on an ordered channel (in a single commit). On an ordered channel the packet must have the next receive sequence.
+// The packet is identified by its destination, which is the solo machine side of the channel.
+func (cs *ChainStorage) ReceivePacket(packet channeltypes.Packet, acknowledgement []byte) error {
+	if err := cs.CheckReceivePacket(packet); err != nil {
+		return err
+	}
+
+	store := cs.packetsStore()
+	if cs.orderedChannel(packet.DestinationPort, packet.DestinationChannel) {
+		store.Set(host.NextSequenceRecvKey(packet.DestinationPort, packet.DestinationChannel), sdk.Uint64ToBigEndian(packet.Sequence+1))
+	} else {
+		store.Set(host.PacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), []byte{byte(1)})
+	}
+	store.Set(host.PacketAcknowledgementKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), acknowledgement)
+	cs.parent.Commit()
+
+	return nil
+}
+
+// CheckReceivePacket checks that the packet can be received, without changing anything: on an ordered channel it must have the
+// next receive sequence, on an unordered channel it must not have been received before
+// It is checked before the application handles the packet, so a packet that cannot be received is not applied either.
+func (cs *ChainStorage) CheckReceivePacket(packet channeltypes.Packet) error {
+	if cs.orderedChannel(packet.DestinationPort, packet.DestinationChannel) {
+		nextSequenceRecv := cs.NextSequenceRecv(packet.DestinationPort, packet.DestinationChannel)
+		if packet.Sequence != nextSequenceRecv {
+			return fmt.Errorf("packet sequence %d is not the next receive sequence %d of the ordered channel %s/%s", packet.Sequence, nextSequenceRecv, packet.DestinationPort, packet.DestinationChannel)
+		}
+		return nil
+	}
+
+	if cs.HasPacketReceipt(packet.DestinationPort, packet.DestinationChannel, packet.Sequence) {
+		return fmt.Errorf("packet %d on %s/%s has already been received", packet.Sequence, packet.DestinationPort, packet.DestinationChannel)
+	}
+
+	return nil
+}
+
+// SendPacket stores the commitment of a packet sent by the solo machine, together with the packet itself, and increments the next send sequence (in a single commit)
+// The packet must have the next send sequence of its channel.
+func (cs *ChainStorage) SendPacket(packet channeltypes.Packet, commitment []byte) error {
+	if err := cs.sendPacket(packet, commitment); err != nil {
+		return err
+	}
+	cs.parent.Commit()
+
+	return nil
+}
+
+// sendPacket does the same as SendPacket, without committing
+// A packet with a sequence reserved by ReserveSendSequence is stored too, without changing the next send sequence.
+func (cs *ChainStorage) sendPacket(packet channeltypes.Packet, commitment []byte) error {
+	store := cs.packetsStore()
+	nextSequenceSend := cs.NextSequenceSend(packet.SourcePort, packet.SourceChannel)
+	reservedKey := reservedSequenceKey(packet.SourcePort, packet.SourceChannel, packet.Sequence)
+	reserved := store.Has(reservedKey)
+	if packet.Sequence != nextSequenceSend && !reserved {
+		return fmt.Errorf("packet sequence %d is not the next send sequence %d of %s/%s", packet.Sequence, nextSequenceSend, packet.SourcePort, packet.SourceChannel)
+	}
+
+	packetBz, err := cs.parent.cdc.Marshal(&packet)
+	if err != nil {
+		return err
+	}
+
+	store.Set(host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), commitment)
+	store.Set(sentPacketKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), packetBz)
+	if reserved {
+		store.Delete(reservedKey)
+	} else {
+		store.Set(host.NextSequenceSendKey(packet.SourcePort, packet.SourceChannel), sdk.Uint64ToBigEndian(nextSequenceSend+1))
+	}
+
+	return nil
+}
+
+// ReserveSendSequence takes the next send sequence of the channel for a packet that is sent later, so no other packet gets it
+// A reserved sequence that is never sent is a gap in the sequences, so it is only meant for unordered channels.
+func (cs *ChainStorage) ReserveSendSequence(portID string, channelID string) uint64 {
+	store := cs.packetsStore()
+	sequence := cs.NextSequenceSend(portID, channelID)
+	store.Set(reservedSequenceKey(portID, channelID, sequence), []byte{byte(1)})
+	store.Set(host.NextSequenceSendKey(portID, channelID), sdk.Uint64ToBigEndian(sequence+1))
+	cs.parent.Commit()
+
+	return sequence
+}
+
+// PacketCommitment returns the commitment of a sent packet, as long as it has not been acknowledged
+func (cs *ChainStorage) PacketCommitment(portID string, channelID string, sequence uint64) ([]byte, bool) {
+	bz := cs.packetsStore().Get(host.PacketCommitmentKey(portID, channelID, sequence))
+	if bz == nil {
+		return nil, false
+	}
+
+	return bz, true
+}
+
+// SentPacket returns a packet sent by the solo machine (also after it has been acknowledged)
+func (cs *ChainStorage) SentPacket(portID string, channelID string, sequence uint64) (channeltypes.Packet, error) {
+	bz := cs.packetsStore().Get(sentPacketKey(portID, channelID, sequence))
+	if bz == nil {
+		return channeltypes.Packet{}, fmt.Errorf("packet %d on %s/%s not found", sequence, portID, channelID)
+	}
+
+	var packet channeltypes.Packet
+	if err := cs.parent.cdc.Unmarshal(bz, &packet); err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	return packet, nil
+}
+
+// AcknowledgePacket stores the acknowledgement the chain wrote for a sent packet and deletes its commitment (in a single commit)
+// On an ordered channel the packet must have the next acknowledgement sequence, which is incremented.
+func (cs *ChainStorage) AcknowledgePacket(packet channeltypes.Packet, acknowledgement []byte) error {
+	if err := cs.CheckAcknowledgePacket(packet); err != nil {
+		return err
+	}
+
+	store := cs.packetsStore()
+	if cs.orderedChannel(packet.SourcePort, packet.SourceChannel) {
+		store.Set(host.NextSequenceAckKey(packet.SourcePort, packet.SourceChannel), sdk.Uint64ToBigEndian(packet.Sequence+1))
+	}
+	store.Set(counterpartyAcknowledgementKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), acknowledgement)
+	store.Delete(host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence))
+	cs.parent.Commit()
+
+	return nil
+}
+
+// CheckAcknowledgePacket checks that the sent packet can be acknowledged, without changing anything: it must still have its commitment,
+// and on an ordered channel it must have the next acknowledgement sequence
+// It is checked before the application handles the acknowledgement, so an acknowledgement that cannot be stored is not applied either.
+func (cs *ChainStorage) CheckAcknowledgePacket(packet channeltypes.Packet) error {
+	if _, ok := cs.PacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence); !ok {
+		return fmt.Errorf("packet %d on %s/%s has no commitment, it has already been acknowledged or timed out", packet.Sequence, packet.SourcePort, packet.SourceChannel)
+	}
+	if cs.orderedChannel(packet.SourcePort, packet.SourceChannel) {
+		nextSequenceAck := cs.NextSequenceAck(packet.SourcePort, packet.SourceChannel)
+		if packet.Sequence != nextSequenceAck {
+			return fmt.Errorf("packet sequence %d is not the next acknowledgement sequence %d of the ordered channel %s/%s", packet.Sequence, nextSequenceAck, packet.SourcePort, packet.SourceChannel)
+		}
+	}
+
+	return nil
+}
+
+// CounterpartyAcknowledgement returns the acknowledgement the chain wrote for a sent packet
+func (cs *ChainStorage) CounterpartyAcknowledgement(portID string, channelID string, sequence uint64) ([]byte, bool) {
+	bz := cs.packetsStore().Get(counterpartyAcknowledgementKey(portID, channelID, sequence))
+	if bz == nil {
+		return nil, false
+	}
+
+	return bz, true
+}
+
+// PacketCommitments returns the commitments of the sent packets on the channel that have not been acknowledged or timed out, ordered by sequence
+func (cs *ChainStorage) PacketCommitments(portID string, channelID string) ([]channeltypes.PacketState, error) {
+	commitmentsPrefix := []byte(host.PacketCommitmentPrefixPath(portID, channelID) + "/")
+	iterator := storetypes.KVStorePrefixIterator(cs.packetsStore(), commitmentsPrefix)
+	defer iterator.Close()
+
+	var commitments []channeltypes.PacketState
+	for ; iterator.Valid(); iterator.Next() {
+		sequence, err := strconv.ParseUint(string(iterator.Key()[len(commitmentsPrefix):]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		commitments = append(commitments, channeltypes.NewPacketState(portID, channelID, sequence, iterator.Value()))
+	}
+
+	// The keys are ordered as strings, not by sequence
+	slices.SortFunc(commitments, func(a, b channeltypes.PacketState) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return commitments, nil
+}
+
+// SentPackets returns all the packets the solo machine has sent on the channel, ordered by sequence
+func (cs *ChainStorage) SentPackets(portID string, channelID string) ([]channeltypes.Packet, error) {
+	iterator := storetypes.KVStorePrefixIterator(cs.packetsStore(), append([]byte(sentPacketsPrefix), host.PacketCommitmentPrefixPath(portID, channelID)+"/"...))
+	defer iterator.Close()
+
+	var packets []channeltypes.Packet
+	for ; iterator.Valid(); iterator.Next() {
+		var packet channeltypes.Packet
+		if err := cs.parent.cdc.Unmarshal(iterator.Value(), &packet); err != nil {
+			return nil, err
+		}
+		packets = append(packets, packet)
+	}
+
+	slices.SortFunc(packets, func(a, b channeltypes.Packet) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return packets, nil
+}
+
+// TimeoutPacket records that a sent packet timed out and deletes its commitment (in a single commit)
+// A timeout on an ordered channel closes the solo machine end of the channel, like on a chain.
+func (cs *ChainStorage) TimeoutPacket(packet channeltypes.Packet) {
+	store := cs.packetsStore()
+	store.Set(timeoutKey(packet.SourcePort, packet.SourceChannel, packet.Sequence), []byte{byte(1)})
+	store.Delete(host.PacketCommitmentKey(packet.SourcePort, packet.SourceChannel, packet.Sequence))
+	if cs.orderedChannel(packet.SourcePort, packet.SourceChannel) {
+		cs.closeChannel(packet.SourcePort, packet.SourceChannel)
+	}
+	cs.parent.Commit()
+}
+
+// PacketTimedOut returns true if the sent packet has timed out
+func (cs *ChainStorage) PacketTimedOut(portID string, channelID string, sequence uint64) bool {
+	return cs.packetsStore().Has(timeoutKey(portID, channelID, sequence))
+}
+
+func reservedSequenceKey(portID string, channelID string, sequence uint64) []byte {
+	return append([]byte(reservedSequencesPrefix), host.PacketCommitmentKey(portID, channelID, sequence)...)
+}
+
+func sentPacketKey(portID string, channelID string, sequence uint64) []byte {
+	return append([]byte(sentPacketsPrefix), host.PacketCommitmentKey(portID, channelID, sequence)...)
+}
+
+// counterpartyAcknowledgementKey is keyed by the solo machine side of the channel (the source of the packet)
+func counterpartyAcknowledgementKey(portID string, channelID string, sequence uint64) []byte {
+	return append([]byte(counterpartyAcknowledgementsPrefix), host.PacketAcknowledgementKey(portID, channelID, sequence)...)
+}
+
+func timeoutKey(portID string, channelID string, sequence uint64) []byte {
+	return append([]byte(timeoutsPrefix), host.PacketCommitmentKey(portID, channelID, sequence)...)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	dbm "github.com/cosmos/cosmos-db"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+const (
+	testChainName = "test-chain"
+	testPortID    = "icacontroller-test"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	return NewStorage(dbm.NewMemDB(), zap.NewNop(), utils.SetupCodec())
+}
+
+// newTestChannel creates the solo machine end of an open channel with the ordering
+func newTestChannel(t *testing.T, cs *ChainStorage, ordering channeltypes.Order) string {
+	t.Helper()
+	channelID := cs.CreateChannel(testPortID)
+	cs.SetChannel(testPortID, channelID, channeltypes.NewChannel(channeltypes.OPEN, ordering, channeltypes.NewCounterparty("host", "channel-7"), []string{"connection-0"}, "version"))
+
+	return channelID
+}
+
+func sentTestPacket(channelID string, sequence uint64) channeltypes.Packet {
+	return channeltypes.NewPacket([]byte("data"), sequence, testPortID, channelID, "host", "channel-7", clienttypes.NewHeight(1, 100), 0)
+}
+
+func receivedTestPacket(channelID string, sequence uint64) channeltypes.Packet {
+	return channeltypes.NewPacket([]byte("data"), sequence, "host", "channel-7", testPortID, channelID, clienttypes.NewHeight(1, 100), 0)
+}
+
+func TestSendPacketSequences(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.UNORDERED)
+
+	require.Equal(t, uint64(1), cs.NextSequenceSend(testPortID, channelID))
+	require.Error(t, cs.SendPacket(sentTestPacket(channelID, 2), []byte("commitment")))
+
+	require.NoError(t, cs.SendPacket(sentTestPacket(channelID, 1), []byte("commitment-1")))
+	require.Equal(t, uint64(2), cs.NextSequenceSend(testPortID, channelID))
+	commitment, ok := cs.PacketCommitment(testPortID, channelID, 1)
+	require.True(t, ok)
+	require.Equal(t, []byte("commitment-1"), commitment)
+
+	// A reserved sequence is sent later, without taking the sequence of the packets sent in between
+	reserved := cs.ReserveSendSequence(testPortID, channelID)
+	require.Equal(t, uint64(2), reserved)
+	require.NoError(t, cs.SendPacket(sentTestPacket(channelID, 3), []byte("commitment-3")))
+	require.NoError(t, cs.SendPacket(sentTestPacket(channelID, reserved), []byte("commitment-2")))
+	require.Equal(t, uint64(4), cs.NextSequenceSend(testPortID, channelID))
+	require.Error(t, cs.SendPacket(sentTestPacket(channelID, reserved), []byte("commitment-2")), "a reserved sequence can only be sent once")
+
+	packets, err := cs.SentPackets(testPortID, channelID)
+	require.NoError(t, err)
+	require.Len(t, packets, 3)
+	for i, packet := range packets {
+		require.Equal(t, uint64(i+1), packet.Sequence)
+	}
+}
+
+func TestReceivePacketOrdered(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.ORDERED)
+
+	require.Error(t, cs.ReceivePacket(receivedTestPacket(channelID, 2), []byte("ack")), "packets are received in order")
+
+	require.NoError(t, cs.ReceivePacket(receivedTestPacket(channelID, 1), []byte("ack-1")))
+	require.Equal(t, uint64(2), cs.NextSequenceRecv(testPortID, channelID))
+	require.False(t, cs.HasPacketReceipt(testPortID, channelID, 1), "ordered channels do not write receipts")
+	ack, ok := cs.PacketAcknowledgement(testPortID, channelID, 1)
+	require.True(t, ok)
+	require.Equal(t, []byte("ack-1"), ack)
+
+	require.Error(t, cs.ReceivePacket(receivedTestPacket(channelID, 1), []byte("ack-1")), "a packet is only received once")
+	require.NoError(t, cs.ReceivePacket(receivedTestPacket(channelID, 2), []byte("ack-2")))
+	require.Equal(t, uint64(3), cs.NextSequenceRecv(testPortID, channelID))
+}
+
+func TestReceivePacketUnordered(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.UNORDERED)
+
+	require.NoError(t, cs.ReceivePacket(receivedTestPacket(channelID, 2), []byte("ack-2")))
+	require.True(t, cs.HasPacketReceipt(testPortID, channelID, 2))
+	require.Error(t, cs.ReceivePacket(receivedTestPacket(channelID, 2), []byte("ack-2")), "a packet is only received once")
+	require.False(t, cs.HasPacketReceipt(testPortID, channelID, 1))
+	require.Equal(t, uint64(1), cs.NextSequenceRecv(testPortID, channelID), "unordered channels do not use the next receive sequence")
+}
+
+func TestAcknowledgePacketOrdered(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.ORDERED)
+	for sequence := uint64(1); sequence <= 2; sequence++ {
+		require.NoError(t, cs.SendPacket(sentTestPacket(channelID, sequence), []byte("commitment")))
+	}
+
+	require.Error(t, cs.AcknowledgePacket(sentTestPacket(channelID, 2), []byte("ack-2")), "packets are acknowledged in order")
+	_, ok := cs.PacketCommitment(testPortID, channelID, 2)
+	require.True(t, ok)
+
+	require.NoError(t, cs.AcknowledgePacket(sentTestPacket(channelID, 1), []byte("ack-1")))
+	require.Equal(t, uint64(2), cs.NextSequenceAck(testPortID, channelID))
+	_, ok = cs.PacketCommitment(testPortID, channelID, 1)
+	require.False(t, ok)
+	ack, ok := cs.CounterpartyAcknowledgement(testPortID, channelID, 1)
+	require.True(t, ok)
+	require.Equal(t, []byte("ack-1"), ack)
+
+	require.NoError(t, cs.AcknowledgePacket(sentTestPacket(channelID, 2), []byte("ack-2")))
+	require.Equal(t, uint64(3), cs.NextSequenceAck(testPortID, channelID))
+	require.Error(t, cs.AcknowledgePacket(sentTestPacket(channelID, 2), []byte("ack-2")), "a packet is only acknowledged once")
+	commitments, err := cs.PacketCommitments(testPortID, channelID)
+	require.NoError(t, err)
+	require.Empty(t, commitments)
+}
+
+func TestTimeoutPacketOrderedClosesChannel(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.ORDERED)
+	require.NoError(t, cs.SendPacket(sentTestPacket(channelID, 1), []byte("commitment")))
+
+	cs.TimeoutPacket(sentTestPacket(channelID, 1))
+
+	require.True(t, cs.PacketTimedOut(testPortID, channelID, 1))
+	_, ok := cs.PacketCommitment(testPortID, channelID, 1)
+	require.False(t, ok)
+	channel, ok := cs.Channel(testPortID, channelID)
+	require.True(t, ok)
+	require.Equal(t, channeltypes.CLOSED, channel.State)
+}
+
+func TestTimeoutPacketUnorderedKeepsChannelOpen(t *testing.T) {
+	cs := newTestStorage(t).GetChainStorage(testChainName)
+	channelID := newTestChannel(t, cs, channeltypes.UNORDERED)
+	require.NoError(t, cs.SendPacket(sentTestPacket(channelID, 1), []byte("commitment")))
+
+	cs.TimeoutPacket(sentTestPacket(channelID, 1))
+
+	require.True(t, cs.PacketTimedOut(testPortID, channelID, 1))
+	channel, ok := cs.Channel(testPortID, channelID)
+	require.True(t, ok)
+	require.Equal(t, channeltypes.OPEN, channel.State)
+}
@@ -2,12 +2,11 @@ package storage
 
 import (
 	"cosmossdk.io/store/metrics"
+	pruningtypes "cosmossdk.io/store/pruning/types"
 	"cosmossdk.io/store/rootmulti"
 	storetypes "cosmossdk.io/store/types"
 	dbm "github.com/cosmos/cosmos-db"
 	"github.com/cosmos/cosmos-sdk/codec"
-	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
-	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gjermundgaraba/solo-machine/utils"
 	"go.uber.org/zap"
@@ -16,8 +15,14 @@ import (
 const (
 	soloMachineStorePrefix = "solo-machine-store"
 	chainsStoragePrefix    = "chains"
+	// Per-chain keys are kept with the other keys in the solo machine store, as chain-keys/<chain-name>/<key>
+	chainKeysPrefix = "chain-keys/"
 
 	privateKeyKey            = "private-key"
+	pendingPrivateKeyKey     = "pending-private-key"
+	mnemonicKey              = "mnemonic"
+	pendingMnemonicKey       = "pending-mnemonic"
+	keyAlgorithmKey          = "key-algorithm"
 	nextLightClientNumberKey = "next-light-client-number"
 	nextConnectionNumberKey  = "next-connection-number"
 	nextChannelNumberKey     = "next-channel-number"
@@ -28,8 +33,6 @@ type Storage struct {
 	store  *rootmulti.Store
 	cdc    codec.Codec
 
-	privateKey            cryptotypes.PrivKey
-	publicKey             cryptotypes.PubKey
 	nextLightClientNumber uint64
 	nextConnectionNumber  uint64
 	nextChannelNumber     uint64
@@ -48,6 +51,10 @@ func NewStorage(db dbm.DB, logger *zap.Logger, cdc codec.Codec) *Storage {
 	store.MountStoreWithDB(soloMachineStoreKey, storetypes.StoreTypeIAVL, nil)
 	store.MountStoreWithDB(rootChainsStoreKey, storetypes.StoreTypeIAVL, nil)
 
+	// The solo machine has no use for old versions
+	// Pruning does not wipe old keys from disk: versions are pruned in batches and the database keeps deleted data until compaction.
+	store.SetPruning(pruningtypes.NewPruningOptions(pruningtypes.PruningEverything))
+
 	if err := store.LoadLatestVersion(); err != nil {
 		panic(err)
 	}
@@ -59,16 +66,6 @@ func NewStorage(db dbm.DB, logger *zap.Logger, cdc codec.Codec) *Storage {
 		soloMachineStoreKey: soloMachineStoreKey,
 	}).getSoloMachineStorage()
 
-	privKeyBz := soloMachineStorage.Get([]byte(privateKeyKey))
-	if privKeyBz == nil {
-		privKeyBz = secp256k1.GenPrivKey().Bytes()
-		soloMachineStorage.Set([]byte(privateKeyKey), privKeyBz)
-	}
-	privateKey := &secp256k1.PrivKey{
-		Key: privKeyBz,
-	}
-	publicKey := privateKey.PubKey()
-
 	nextLightClientNumber := uint64(0)
 	nextLightClientNumberBz := soloMachineStorage.Get([]byte(nextLightClientNumberKey))
 	if nextLightClientNumberBz != nil {
@@ -92,8 +89,6 @@ func NewStorage(db dbm.DB, logger *zap.Logger, cdc codec.Codec) *Storage {
 		store:  store,
 		cdc:    cdc,
 
-		privateKey:            privateKey,
-		publicKey:             publicKey,
 		nextLightClientNumber: nextLightClientNumber,
 		nextConnectionNumber:  nextConnectionNumber,
 		nextChannelNumber:     nextChannelNumber,
@@ -107,14 +102,6 @@ func (s *Storage) Commit() {
 	s.store.Commit()
 }
 
-func (s *Storage) GetPublicKey() cryptotypes.PubKey {
-	return s.publicKey
-}
-
-func (s *Storage) Sign(data []byte) ([]byte, error) {
-	return s.privateKey.Sign(data)
-}
-
 func (s *Storage) getSoloMachineStorage() storetypes.CommitKVStore {
 	return s.store.GetCommitKVStore(s.soloMachineStoreKey)
 }
@@ -0,0 +1,292 @@
+package solomachine
+
+import (
+	"fmt"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v8/modules/core/23-commitment/types"
+	host "github.com/cosmos/ibc-go/v8/modules/core/24-host"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"go.uber.org/zap"
+)
+
+// TimeoutPackets times out the packets sent by the solo machine that the chain has not received before their timeout
+// (or before the channel was closed on the chain). Packets the chain did receive are acknowledged instead.
+// A timeout on an ordered channel closes the channel, and the packets sent after it time out too. It returns the number of packets that timed out.
+func (sm *SoloMachine) TimeoutPackets(chainName string) (int, error) {
+	pending, err := sm.processPendingPackets(chainName)
+	if err != nil {
+		return 0, err
+	}
+
+	sm.logger.Info("Packets timed out", zap.String("chain", chainName), zap.Int("timed-out", pending.timedOut))
+
+	return pending.timedOut, nil
+}
+
+// pendingPackets is the outcome of processing the packets waiting for acknowledgement
+type pendingPackets struct {
+	proofHeight  clienttypes.Height
+	acknowledged int
+	timedOut     int
+	// unreceived are the packets the chain has not received, but still can
+	unreceived []channeltypes.Packet
+}
+
+// processPendingPackets goes through the packets waiting for acknowledgement: the ones the chain has received are acknowledged,
+// the ones that can no longer be received time out, and the rest are returned as unreceived. The channels closed by a timeout
+// are then closed on the chain too.
+func (sm *SoloMachine) processPendingPackets(chainName string) (pendingPackets, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	// Closed channels can still have packets waiting for acknowledgement, e.g. the packets after a timeout on an ordered channel
+	channels, err := sm.channelsInState(chainName, channeltypes.OPEN, channeltypes.CLOSED)
+	if err != nil {
+		return pendingPackets{}, err
+	}
+
+	channelCommitments := make([][]channeltypes.PacketState, len(channels))
+	total := 0
+	for i, channel := range channels {
+		channelCommitments[i], err = chainStorage.PacketCommitments(channel.PortId, channel.ChannelId)
+		if err != nil {
+			return pendingPackets{}, err
+		}
+		total += len(channelCommitments[i])
+	}
+	if total == 0 {
+		sm.logger.Info("No packets waiting for acknowledgement", zap.String("chain", chainName))
+		return pendingPackets{}, sm.closeCounterpartyChannels(chainName)
+	}
+
+	if err := sm.UpdateLightClient(chainName); err != nil {
+		return pendingPackets{}, err
+	}
+	lightClientState, err := chainStorage.LightClientState()
+	if err != nil {
+		return pendingPackets{}, err
+	}
+	pending := pendingPackets{proofHeight: lightClientState.LatestHeight}
+	consensusState, err := chainStorage.GetLightConsensusState(pending.proofHeight)
+	if err != nil {
+		return pendingPackets{}, err
+	}
+
+	for i, channel := range channels {
+		if len(channelCommitments[i]) == 0 {
+			continue
+		}
+
+		if err := sm.processChannelPendingPackets(chainName, channel, channelCommitments[i], consensusState.GetTimestamp(), &pending); err != nil {
+			return pending, err
+		}
+	}
+
+	return pending, sm.closeCounterpartyChannels(chainName)
+}
+
+// closeCounterpartyChannels relays the channel close confirm to the chain for every channel the solo machine has closed (after a timeout
+// on an ordered channel) that is still open on the chain, so a close confirm that failed in an earlier run is retried
+func (sm *SoloMachine) closeCounterpartyChannels(chainName string) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channels, err := chainStorage.Channels()
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		if channel.State != channeltypes.CLOSED {
+			continue
+		}
+
+		counterpartyChannel, err := sm.r.QueryChannel(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId)
+		if err != nil {
+			return err
+		}
+		if counterpartyChannel.State == channeltypes.CLOSED {
+			continue
+		}
+
+		if err := sm.channelCloseConfirm(chainName, channel.PortId, channel.ChannelId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processChannelPendingPackets processes the packets waiting for acknowledgement on a channel, see processPendingPackets
+// The chain has received a packet on an unordered channel if it has a receipt for it, and on an ordered channel if its next receive sequence is past it.
+func (sm *SoloMachine) processChannelPendingPackets(chainName string, channel channeltypes.IdentifiedChannel, commitments []channeltypes.PacketState, chainTimestamp uint64, pending *pendingPackets) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	var err error
+	channelClosed := channel.State == channeltypes.CLOSED
+	if !channelClosed {
+		channelClosed, err = sm.counterpartyChannelClosed(chainName, channel.Counterparty.PortId, channel.Counterparty.ChannelId, pending.proofHeight)
+		if err != nil {
+			return err
+		}
+	}
+
+	ordered := channel.Ordering == channeltypes.ORDERED
+	var nextSequenceRecvBz, nextSequenceRecvProof []byte
+	if ordered {
+		nextSequenceRecvBz, nextSequenceRecvProof, err = sm.r.QueryProof(chainName, host.NextSequenceRecvKey(channel.Counterparty.PortId, channel.Counterparty.ChannelId), pending.proofHeight)
+		if err != nil {
+			return err
+		}
+		if len(nextSequenceRecvBz) != 8 {
+			return fmt.Errorf("no next receive sequence for the ordered channel %s on the chain", channel.Counterparty.ChannelId)
+		}
+	}
+
+	unreceivedBefore := len(pending.unreceived)
+	for _, commitment := range commitments {
+		packet, err := chainStorage.SentPacket(commitment.PortId, commitment.ChannelId, commitment.Sequence)
+		if err != nil {
+			return err
+		}
+
+		var received bool
+		var receiptProof []byte
+		if ordered {
+			received = packet.Sequence < sdk.BigEndianToUint64(nextSequenceRecvBz)
+		} else {
+			var receipt []byte
+			receipt, receiptProof, err = sm.r.QueryProof(chainName, host.PacketReceiptKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), pending.proofHeight)
+			if err != nil {
+				return err
+			}
+			received = len(receipt) != 0
+		}
+		if received {
+			// The chain received the packet, but the acknowledgement never made it back to the solo machine
+			if err := sm.acknowledgeReceivedPacket(chainName, packet, pending.proofHeight); err != nil {
+				return err
+			}
+			pending.acknowledged++
+			continue
+		}
+
+		if !channelClosed && !packetTimedOutOnChain(packet, pending.proofHeight, chainTimestamp) {
+			pending.unreceived = append(pending.unreceived, packet)
+			continue
+		}
+		if ordered && len(pending.unreceived) > unreceivedBefore {
+			// The packets before it on the ordered channel are relayed first, it times out on a later run if they are received
+			break
+		}
+
+		if ordered {
+			nextSequenceRecvPath, err := ibcMerklePath(host.NextSequenceRecvPath(packet.DestinationPort, packet.DestinationChannel))
+			if err != nil {
+				return err
+			}
+			if err := chainStorage.VerifyMembership(sm.verificationContext(), pending.proofHeight, nextSequenceRecvProof, nextSequenceRecvPath, nextSequenceRecvBz); err != nil {
+				return fmt.Errorf("failed to verify the next receive sequence of packet %d: %w", packet.Sequence, err)
+			}
+		} else {
+			receiptPath, err := ibcMerklePath(host.PacketReceiptPath(packet.DestinationPort, packet.DestinationChannel, packet.Sequence))
+			if err != nil {
+				return err
+			}
+			if err := chainStorage.VerifyNonMembership(sm.verificationContext(), pending.proofHeight, receiptProof, receiptPath); err != nil {
+				return fmt.Errorf("failed to verify the absence of the receipt of packet %d: %w", packet.Sequence, err)
+			}
+		}
+
+		if err := sm.onTimeoutPacket(chainName, packet); err != nil {
+			return err
+		}
+		chainStorage.TimeoutPacket(packet)
+		sm.logger.Warn("Packet timed out", zap.String("chain", chainName), zap.String("port-id", packet.SourcePort), zap.String("channel-id", packet.SourceChannel), zap.Uint64("packet-sequence", packet.Sequence))
+		pending.timedOut++
+
+		if ordered && !channelClosed {
+			// The chain can no longer receive the packets sent after it either
+			sm.logger.Warn("The ordered channel has been closed by the timeout, the packets sent after it will time out too", zap.String("chain", chainName), zap.String("port-id", channel.PortId), zap.String("channel-id", channel.ChannelId))
+			channelClosed = true
+		}
+	}
+
+	return nil
+}
+
+// counterpartyChannelClosed checks (and verifies against the light client) if the channel on the chain has been closed
+func (sm *SoloMachine) counterpartyChannelClosed(chainName string, portID string, channelID string, proofHeight clienttypes.Height) (bool, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	channelBz, channelProof, err := sm.r.QueryProof(chainName, host.ChannelKey(portID, channelID), proofHeight)
+	if err != nil {
+		return false, err
+	}
+	var channel channeltypes.Channel
+	if err := sm.cdc.Unmarshal(channelBz, &channel); err != nil {
+		return false, err
+	}
+	if channel.State != channeltypes.CLOSED {
+		return false, nil
+	}
+
+	channelPath, err := ibcMerklePath(host.ChannelPath(portID, channelID))
+	if err != nil {
+		return false, err
+	}
+	if err := chainStorage.VerifyMembership(sm.verificationContext(), proofHeight, channelProof, channelPath, channelBz); err != nil {
+		return false, fmt.Errorf("failed to verify the closed channel %s: %w", channelID, err)
+	}
+
+	sm.logger.Warn("The channel has been closed on the chain, all packets waiting for acknowledgement will time out", zap.String("chain", chainName), zap.String("channel-id", channelID))
+
+	return true, nil
+}
+
+// acknowledgeReceivedPacket processes the acknowledgement the chain wrote for a sent packet, after verifying it against the light client
+// The chain only stores the commitment of the acknowledgement, so the acknowledgement itself is found from the write_acknowledgement event.
+func (sm *SoloMachine) acknowledgeReceivedPacket(chainName string, packet channeltypes.Packet, proofHeight clienttypes.Height) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	ackCommitment, ackProof, err := sm.r.QueryProof(chainName, host.PacketAcknowledgementKey(packet.DestinationPort, packet.DestinationChannel, packet.Sequence), proofHeight)
+	if err != nil {
+		return err
+	}
+	if len(ackCommitment) == 0 {
+		sm.logger.Info("Packet received by the chain, but not acknowledged yet", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+		return nil
+	}
+
+	acknowledgement, err := sm.r.QueryWriteAcknowledgement(chainName, packet)
+	if err != nil {
+		return err
+	}
+
+	ackPath, err := ibcMerklePath(host.PacketAcknowledgementPath(packet.DestinationPort, packet.DestinationChannel, packet.Sequence))
+	if err != nil {
+		return err
+	}
+	if err := chainStorage.VerifyMembership(sm.verificationContext(), proofHeight, ackProof, ackPath, channeltypes.CommitAcknowledgement(acknowledgement)); err != nil {
+		return fmt.Errorf("failed to verify the acknowledgement of packet %d: %w", packet.Sequence, err)
+	}
+
+	return sm.acknowledgePacket(chainName, packet, acknowledgement)
+}
+
+// packetTimedOutOnChain checks the packet timeout against the height and time of the chain (from the light client)
+func packetTimedOutOnChain(packet channeltypes.Packet, chainHeight clienttypes.Height, chainTimestamp uint64) bool {
+	if !packet.TimeoutHeight.IsZero() && chainHeight.GTE(packet.TimeoutHeight) {
+		return true
+	}
+
+	return packet.TimeoutTimestamp != 0 && chainTimestamp >= packet.TimeoutTimestamp
+}
+
+// ibcMerklePath prefixes the path with the IBC store key, which is how paths in the chain state are proven
+func ibcMerklePath(path string) (commitmenttypes.MerklePath, error) {
+	return commitmenttypes.ApplyPrefix(
+		commitmenttypes.NewMerklePrefix([]byte(exported.StoreKey)),
+		commitmenttypes.NewMerklePath(path),
+	)
+}
@@ -1,65 +1,286 @@
 package solomachine
 
 import (
+	"fmt"
 	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"go.uber.org/zap"
 	"strconv"
 	"time"
 )
 
-func (sm *SoloMachine) Transfer(chainName string, sender string, receiver string, denom string, amount uint64) error {
+// DefaultTimeoutHeightOffset is the number of blocks after the latest chain height a transfer times out by default
+const DefaultTimeoutHeightOffset = 1000
+
+// PacketTimeoutOptions are the timeouts of a packet sent by the solo machine
+// The absolute timeouts take precedence over the offsets, which are relative to the latest height and time of the chain (from the light client).
+// A packet needs a timeout, so at least one of the timeouts must end up being set.
+type PacketTimeoutOptions struct {
+	TimeoutHeightOffset    uint64
+	TimeoutTimestampOffset time.Duration
+	TimeoutHeight          clienttypes.Height
+	TimeoutTimestamp       uint64 // unix time in nanoseconds
+}
+
+// TransferOptions are the optional parts of an ICS20 transfer
+type TransferOptions struct {
+	PacketTimeoutOptions
+	Memo string
+}
+
+func DefaultTransferOptions() TransferOptions {
+	return TransferOptions{
+		PacketTimeoutOptions: PacketTimeoutOptions{TimeoutHeightOffset: DefaultTimeoutHeightOffset},
+	}
+}
+
+// Transfer sends an ICS20 packet to the chain and returns the acknowledgement the chain wrote for it
+// An error acknowledgement means the transfer module on the chain rejected the tokens.
+func (sm *SoloMachine) Transfer(chainName string, sender string, receiver string, denom string, amount uint64, options TransferOptions) (channeltypes.Acknowledgement, error) {
 	chainStorage := sm.storage.GetChainStorage(chainName)
 
 	if err := sm.UpdateCounterpartyLightClient(chainName); err != nil {
-		return err
+		return channeltypes.Acknowledgement{}, err
 	}
 	if err := sm.UpdateLightClient(chainName); err != nil {
-		return err
+		return channeltypes.Acknowledgement{}, err
 	}
 
 	// Give some time for stuff to update
 	time.Sleep(5 * time.Second)
 
-	ics20ChannelID := chainStorage.ICS20ChannelID()
-	counterpartyICS20ChannelID := chainStorage.CounterpartyICS20Channel()
-
 	lightClientState, err := chainStorage.LightClientState()
 	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	counterpartyLightClientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	sequence := counterpartyLightClientState.Sequence
+
+	packetSequence := chainStorage.NextSequenceSend(transfertypes.PortID, chainStorage.ICS20ChannelID())
+	packet, err := sm.newTransferPacket(chainName, lightClientState.LatestHeight, packetSequence, sender, receiver, denom, amount, options)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+	// Stored before it is signed, so a packet that fails to be relayed is still pending and can be relayed again
+	packet, err = sm.sendPacket(chainName, packet)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	commitmentProof, err := sm.GenerateCommitmentProof(chainName, packet, sequence)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	acknowledgement, err := sm.relayPacket(chainName, packet, commitmentProof, lightClientState.LatestHeight)
+	if err != nil {
+		return channeltypes.Acknowledgement{}, err
+	}
+
+	return unmarshalAcknowledgement(packet, acknowledgement)
+}
+
+// relayPacket relays a stored packet sent by the solo machine to the chain and processes the acknowledgement
+// It returns the acknowledgement as written by the application on the chain.
+func (sm *SoloMachine) relayPacket(chainName string, packet channeltypes.Packet, commitmentProof []byte, proofHeight clienttypes.Height) ([]byte, error) {
+	acknowledgement, err := sm.r.SendMsgRecvPacket(chainName, packet, commitmentProof, proofHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sm.acknowledgePacket(chainName, packet, acknowledgement); err != nil {
+		return nil, err
+	}
+
+	return acknowledgement, nil
+}
+
+// unmarshalAcknowledgement decodes an ICS-04 acknowledgement, the format the ICS20 and ICA applications on the chain write
+func unmarshalAcknowledgement(packet channeltypes.Packet, acknowledgement []byte) (channeltypes.Acknowledgement, error) {
+	var ack channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return channeltypes.Acknowledgement{}, fmt.Errorf("cannot unmarshal the acknowledgement of packet %d: %w", packet.Sequence, err)
+	}
+
+	return ack, nil
+}
+
+// relayPackets relays stored packets sent by the solo machine in a single tx and processes the acknowledgements
+// It returns the number of packets the chain wrote an acknowledgement for.
+func (sm *SoloMachine) relayPackets(chainName string, packets []channeltypes.Packet, proofHeight clienttypes.Height) (int, error) {
+	packets, err := sm.unreceivedPackets(chainName, packets)
+	if err != nil {
+		return 0, err
+	}
+	if len(packets) == 0 {
+		return 0, nil
+	}
+
+	sequence, err := sm.counterpartySequence(chainName)
+	if err != nil {
+		return 0, err
+	}
+
+	// Each MsgRecvPacket in the tx verifies a signature and increments the sequence of the client on the chain
+	commitmentProofs := make([][]byte, len(packets))
+	for i, packet := range packets {
+		commitmentProofs[i], err = sm.GenerateCommitmentProof(chainName, packet, sequence+uint64(i))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	acknowledgements, err := sm.r.SendMsgRecvPackets(chainName, packets, commitmentProofs, proofHeight)
+	if err != nil {
+		return 0, err
+	}
+
+	acknowledged := 0
+	for i, packet := range packets {
+		if acknowledgements[i] == nil {
+			continue
+		}
+		if err := sm.acknowledgePacket(chainName, packet, acknowledgements[i]); err != nil {
+			return acknowledged, err
+		}
+		acknowledged++
+	}
+
+	return acknowledged, nil
+}
+
+// unreceivedPackets leaves out the packets that are no longer waiting for acknowledgement or that the chain has already received
+// A MsgRecvPacket for a received packet is a no-op on the chain that does not increment the client sequence, which would make
+// the proofs of all the packets after it in the same tx invalid. The received packets are acknowledged by relay timeouts (or flush).
+func (sm *SoloMachine) unreceivedPackets(chainName string, packets []channeltypes.Packet) ([]channeltypes.Packet, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	nextSequenceRecv := make(map[string]uint64)
+	var unreceived []channeltypes.Packet
+	for _, packet := range packets {
+		if _, ok := chainStorage.PacketCommitment(packet.SourcePort, packet.SourceChannel, packet.Sequence); !ok {
+			continue
+		}
+
+		var received bool
+		if channel, ok := chainStorage.Channel(packet.SourcePort, packet.SourceChannel); ok && channel.Ordering == channeltypes.ORDERED {
+			channelKey := packet.DestinationPort + "/" + packet.DestinationChannel
+			if _, ok := nextSequenceRecv[channelKey]; !ok {
+				sequence, err := sm.r.QueryNextSequenceReceive(chainName, packet.DestinationPort, packet.DestinationChannel)
+				if err != nil {
+					return nil, err
+				}
+				nextSequenceRecv[channelKey] = sequence
+			}
+			received = packet.Sequence < nextSequenceRecv[channelKey]
+		} else {
+			var err error
+			received, err = sm.r.QueryPacketReceipt(chainName, packet.DestinationPort, packet.DestinationChannel, packet.Sequence)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if received {
+			sm.logger.Info("Packet already received by the chain, it is acknowledged by relay timeouts or flush instead", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+			continue
+		}
+
+		unreceived = append(unreceived, packet)
+	}
+
+	return unreceived, nil
+}
+
+// acknowledgePacket persists the acknowledgement the chain wrote for a sent packet and deletes the packet commitment
+// The application of the port handles the acknowledgement first, e.g. the transfer application refunds the tokens if it is an error.
+// Only the application parses the acknowledgement, so applications with their own acknowledgement format can be acknowledged too.
+// The packet is checked first, so an acknowledgement the storage would reject (e.g. out of order) is never applied by the application.
+func (sm *SoloMachine) acknowledgePacket(chainName string, packet channeltypes.Packet, acknowledgement []byte) error {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+	if err := chainStorage.CheckAcknowledgePacket(packet); err != nil {
 		return err
 	}
-	timeoutHeight := clienttypes.NewHeight(lightClientState.LatestHeight.RevisionNumber, lightClientState.LatestHeight.RevisionHeight+1000)
+
+	if err := sm.onAcknowledgementPacket(chainName, packet, acknowledgement); err != nil {
+		return err
+	}
+
+	if err := chainStorage.AcknowledgePacket(packet, acknowledgement); err != nil {
+		return err
+	}
+
+	// The error is only known for ICS-04 acknowledgements
+	if ack, err := unmarshalAcknowledgement(packet, acknowledgement); err == nil && !ack.Success() {
+		sm.logger.Warn("Packet acknowledged with an error", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence), zap.String("error", ack.GetError()))
+	} else {
+		sm.logger.Info("Packet acknowledged", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+	}
+
+	return nil
+}
+
+// newTransferPacket creates an ICS20 packet with the given packet sequence on the solo machine ICS20 channel
+func (sm *SoloMachine) newTransferPacket(chainName string, latestHeight clienttypes.Height, sequence uint64, sender string, receiver string, denom string, amount uint64, options TransferOptions) (channeltypes.Packet, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	timeoutHeight, timeoutTimestamp, err := sm.packetTimeout(chainName, latestHeight, options.PacketTimeoutOptions)
+	if err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	fullDenomPath, err := sm.fullDenomPath(denom)
+	if err != nil {
+		return channeltypes.Packet{}, err
+	}
 
 	amountStr := strconv.FormatInt(int64(amount), 10)
 	fungibleTokenPacket := transfertypes.NewFungibleTokenPacketData(
-		denom,
+		fullDenomPath,
 		amountStr,
 		sender,
 		receiver,
-		"",
+		options.Memo,
 	)
 
-	counterpartyLightClientState, err := sm.r.GetClientState(chainName, chainStorage.CounterpartyClientID())
-	if err != nil {
-		return err
-	}
-	sequence := counterpartyLightClientState.Sequence
-
-	packet := channeltypes.NewPacket(
+	return channeltypes.NewPacket(
 		fungibleTokenPacket.GetBytes(),
 		sequence,
 		transfertypes.PortID,
-		ics20ChannelID,
+		chainStorage.ICS20ChannelID(),
 		transfertypes.PortID,
-		counterpartyICS20ChannelID,
+		chainStorage.CounterpartyICS20Channel(),
 		timeoutHeight,
-		0,
-	)
+		timeoutTimestamp,
+	), nil
+}
 
-	commitmentProof, err := sm.GenerateCommitmentProof(chainName, packet, sequence)
-	if err != nil {
-		return err
+// packetTimeout returns the timeout height and timestamp of a packet sent with the options
+// The timeout offsets are added to the given height and the time of the consensus state at that height.
+func (sm *SoloMachine) packetTimeout(chainName string, latestHeight clienttypes.Height, options PacketTimeoutOptions) (clienttypes.Height, uint64, error) {
+	chainStorage := sm.storage.GetChainStorage(chainName)
+
+	timeoutHeight := options.TimeoutHeight
+	if timeoutHeight.IsZero() && options.TimeoutHeightOffset != 0 {
+		timeoutHeight = clienttypes.NewHeight(latestHeight.RevisionNumber, latestHeight.RevisionHeight+options.TimeoutHeightOffset)
+	}
+
+	timeoutTimestamp := options.TimeoutTimestamp
+	if timeoutTimestamp == 0 && options.TimeoutTimestampOffset != 0 {
+		consensusState, err := chainStorage.GetLightConsensusState(latestHeight)
+		if err != nil {
+			return clienttypes.Height{}, 0, err
+		}
+		timeoutTimestamp = consensusState.GetTimestamp() + uint64(options.TimeoutTimestampOffset.Nanoseconds())
+	}
+
+	if timeoutHeight.IsZero() && timeoutTimestamp == 0 {
+		return clienttypes.Height{}, 0, fmt.Errorf("a packet needs a timeout height or a timeout timestamp")
 	}
 
-	return sm.r.SendMsgRecvPacket(chainName, packet, commitmentProof, lightClientState.LatestHeight)
+	return timeoutHeight, timeoutTimestamp, nil
 }
@@ -0,0 +1,63 @@
+package solomachine
+
+import (
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// sendTestPackets sends transfers of 10stake from alice with the sequences 1 to n on the test channel
+func sendTestPackets(t *testing.T, sm *SoloMachine, n int) []channeltypes.Packet {
+	t.Helper()
+	packets := make([]channeltypes.Packet, n)
+	for i := range packets {
+		packet := sentTransferPacket("stake", 10, "alice")
+		packet.Sequence = uint64(i + 1)
+
+		sentPacket, err := sm.applySendPacket(testChainName, packet)
+		require.NoError(t, err)
+		require.NoError(t, sm.storeSentPacket(testChainName, sentPacket))
+		packets[i] = sentPacket
+	}
+
+	return packets
+}
+
+func TestAcknowledgePacketOutOfOrder(t *testing.T) {
+	sm := newTestSoloMachine(t)
+	setTestChannel(sm, channeltypes.ORDERED)
+	require.NoError(t, sm.storage.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	packets := sendTestPackets(t, sm, 2)
+	require.Equal(t, sdkmath.NewInt(80), sm.storage.Balance("alice", "stake"))
+	errorAck := channeltypes.NewErrorAcknowledgement(transfertypes.ErrReceiveDisabled).Acknowledgement()
+
+	require.Error(t, sm.acknowledgePacket(testChainName, packets[1], errorAck))
+	sm.storage.Commit()
+	require.Equal(t, sdkmath.NewInt(80), sm.storage.Balance("alice", "stake"), "an acknowledgement that is not stored is not applied")
+
+	require.NoError(t, sm.acknowledgePacket(testChainName, packets[0], errorAck))
+	require.Equal(t, sdkmath.NewInt(90), sm.storage.Balance("alice", "stake"))
+
+	require.Error(t, sm.acknowledgePacket(testChainName, packets[0], errorAck))
+	sm.storage.Commit()
+	require.Equal(t, sdkmath.NewInt(90), sm.storage.Balance("alice", "stake"), "a redelivered acknowledgement is not applied again")
+
+	require.NoError(t, sm.acknowledgePacket(testChainName, packets[1], errorAck))
+	require.Equal(t, sdkmath.NewInt(100), sm.storage.Balance("alice", "stake"))
+}
+
+func TestAcknowledgePacketTwiceUnordered(t *testing.T) {
+	sm := newTestSoloMachine(t)
+	setTestChannel(sm, channeltypes.UNORDERED)
+	require.NoError(t, sm.storage.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	packets := sendTestPackets(t, sm, 1)
+	errorAck := channeltypes.NewErrorAcknowledgement(transfertypes.ErrReceiveDisabled).Acknowledgement()
+
+	require.NoError(t, sm.acknowledgePacket(testChainName, packets[0], errorAck))
+	require.Error(t, sm.acknowledgePacket(testChainName, packets[0], errorAck))
+	sm.storage.Commit()
+	require.Equal(t, sdkmath.NewInt(100), sm.storage.Balance("alice", "stake"))
+}
@@ -0,0 +1,131 @@
+package solomachine
+
+import (
+	"encoding/json"
+	"fmt"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	"github.com/cosmos/ibc-go/v8/modules/core/exported"
+	"go.uber.org/zap"
+	"strings"
+)
+
+var (
+	_ IBCModule    = transferModule{}
+	_ PacketSender = transferModule{}
+)
+
+// transferModule is the built-in ICS20 application, which moves the tokens in the local ledger the same way as the ibc-go transfer module
+type transferModule struct {
+	sm *SoloMachine
+}
+
+// validateTransferChannelParams checks the channel the same way as the ibc-go transfer module
+func validateTransferChannelParams(order channeltypes.Order, portID string) error {
+	if order != channeltypes.UNORDERED {
+		return fmt.Errorf("invalid channel ordering: expected %s channel, got %s", channeltypes.UNORDERED, order)
+	}
+	if portID != transfertypes.PortID {
+		return fmt.Errorf("invalid port: %s, expected %s", portID, transfertypes.PortID)
+	}
+
+	return nil
+}
+
+func (transferModule) OnChanOpenInit(_ string, order channeltypes.Order, _ []string, portID string, _ string, _ channeltypes.Counterparty, version string) (string, error) {
+	if err := validateTransferChannelParams(order, portID); err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(version) == "" {
+		version = transfertypes.Version
+	}
+	if version != transfertypes.Version {
+		return "", fmt.Errorf("invalid version: expected %s, got %s", transfertypes.Version, version)
+	}
+
+	return version, nil
+}
+
+func (transferModule) OnChanOpenTry(_ string, order channeltypes.Order, _ []string, portID string, _ string, _ channeltypes.Counterparty, counterpartyVersion string) (string, error) {
+	if err := validateTransferChannelParams(order, portID); err != nil {
+		return "", err
+	}
+
+	if counterpartyVersion != transfertypes.Version {
+		return "", fmt.Errorf("invalid counterparty version: expected %s, got %s", transfertypes.Version, counterpartyVersion)
+	}
+
+	return transfertypes.Version, nil
+}
+
+func (transferModule) OnChanOpenAck(_ string, _ string, _ string, _ string, counterpartyVersion string) error {
+	if counterpartyVersion != transfertypes.Version {
+		return fmt.Errorf("invalid counterparty version: expected %s, got %s", transfertypes.Version, counterpartyVersion)
+	}
+
+	return nil
+}
+
+func (transferModule) OnChanOpenConfirm(_ string, _ string, _ string) error {
+	return nil
+}
+
+// OnSendPacket takes the tokens of an outgoing packet from the sender
+func (m transferModule) OnSendPacket(_ string, packet channeltypes.Packet) (channeltypes.Packet, error) {
+	if err := m.sm.sendTransferTokens(packet); err != nil {
+		return channeltypes.Packet{}, err
+	}
+
+	return packet, nil
+}
+
+func (m transferModule) OnRecvPacket(chainName string, packet channeltypes.Packet) (exported.Acknowledgement, error) {
+	return m.sm.onRecvTransferPacket(chainName, packet), nil
+}
+
+// OnAcknowledgementPacket refunds the tokens if the chain wrote an error acknowledgement
+func (m transferModule) OnAcknowledgementPacket(chainName string, packet channeltypes.Packet, acknowledgement []byte) error {
+	var ack channeltypes.Acknowledgement
+	if err := channeltypes.SubModuleCdc.UnmarshalJSON(acknowledgement, &ack); err != nil {
+		return fmt.Errorf("cannot unmarshal ICS-20 transfer packet acknowledgement: %w", err)
+	}
+	if ack.Success() {
+		return nil
+	}
+
+	return m.sm.refundTransferTokens(chainName, packet)
+}
+
+func (m transferModule) OnTimeoutPacket(chainName string, packet channeltypes.Packet) error {
+	return m.sm.refundTransferTokens(chainName, packet)
+}
+
+// onRecvTransferPacket decodes the ICS20 packet data the same way the transfer module does, and returns the acknowledgement for it
+func (sm *SoloMachine) onRecvTransferPacket(chainName string, packet channeltypes.Packet) channeltypes.Acknowledgement {
+	var data transfertypes.FungibleTokenPacketData
+	if err := json.Unmarshal(packet.GetData(), &data); err != nil {
+		sm.logger.Error("Cannot unmarshal ICS-20 transfer packet data", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence))
+		return channeltypes.NewErrorAcknowledgement(fmt.Errorf("cannot unmarshal ICS-20 transfer packet data"))
+	}
+	if err := data.ValidateBasic(); err != nil {
+		sm.logger.Error("Invalid ICS-20 transfer packet data", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence), zap.Error(err))
+		return channeltypes.NewErrorAcknowledgement(err)
+	}
+
+	if err := sm.receiveTransferTokens(packet, data); err != nil {
+		sm.logger.Error("Cannot receive ICS-20 tokens", zap.String("chain", chainName), zap.Uint64("packet-sequence", packet.Sequence), zap.Error(err))
+		return channeltypes.NewErrorAcknowledgement(err)
+	}
+
+	sm.logger.Info("Received tokens",
+		zap.String("chain", chainName),
+		zap.Uint64("packet-sequence", packet.Sequence),
+		zap.String("sender", data.Sender),
+		zap.String("receiver", data.Receiver),
+		zap.String("denom", data.Denom),
+		zap.String("amount", data.Amount),
+	)
+
+	return channeltypes.NewResultAcknowledgement([]byte{byte(1)})
+}
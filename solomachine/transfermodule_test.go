@@ -0,0 +1,157 @@
+package solomachine
+
+import (
+	sdkmath "cosmossdk.io/math"
+	dbm "github.com/cosmos/cosmos-db"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
+	clienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
+	channeltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
+	smstorage "github.com/gjermundgaraba/solo-machine/solomachine/storage"
+	"github.com/gjermundgaraba/solo-machine/utils"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"testing"
+)
+
+const (
+	testChainName           = "test-chain"
+	testChannelID           = "channel-0"
+	testCounterpartyChannel = "channel-7"
+)
+
+// newTestSoloMachine creates a solo machine with in-memory storage and the built-in applications, without a relayer
+func newTestSoloMachine(t *testing.T) *SoloMachine {
+	t.Helper()
+	cdc := utils.SetupCodec()
+	sm := &SoloMachine{
+		logger:  zap.NewNop(),
+		cdc:     cdc,
+		storage: smstorage.NewStorage(dbm.NewMemDB(), zap.NewNop(), cdc),
+		router:  NewRouter(),
+	}
+	sm.router.AddRoute(transfertypes.PortID, transferModule{sm: sm})
+
+	return sm
+}
+
+func newTestTransferModule(t *testing.T) (transferModule, *smstorage.Storage) {
+	t.Helper()
+	sm := newTestSoloMachine(t)
+
+	return transferModule{sm: sm}, sm.storage
+}
+
+// sentTransferPacket is an ICS20 packet sent by the solo machine on the test channel
+func sentTransferPacket(denom string, amount int64, sender string) channeltypes.Packet {
+	data := transfertypes.NewFungibleTokenPacketData(denom, sdkmath.NewInt(amount).String(), sender, "cosmos1receiver", "")
+	return channeltypes.NewPacket(data.GetBytes(), 1, transfertypes.PortID, testChannelID, transfertypes.PortID, testCounterpartyChannel, clienttypes.NewHeight(1, 100), 0)
+}
+
+// receivedTransferPacket is an ICS20 packet sent by the chain to the solo machine on the test channel
+func receivedTransferPacket(denom string, amount int64, receiver string) channeltypes.Packet {
+	data := transfertypes.NewFungibleTokenPacketData(denom, sdkmath.NewInt(amount).String(), "cosmos1sender", receiver, "")
+	return channeltypes.NewPacket(data.GetBytes(), 1, transfertypes.PortID, testCounterpartyChannel, transfertypes.PortID, testChannelID, clienttypes.NewHeight(1, 100), 0)
+}
+
+func TestTransferEscrowAndRefundOnTimeout(t *testing.T) {
+	module, storage := newTestTransferModule(t)
+	escrow := smstorage.EscrowAccount(transfertypes.PortID, testChannelID)
+	require.NoError(t, storage.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+
+	packet := sentTransferPacket("stake", 40, "alice")
+	_, err := module.OnSendPacket(testChainName, packet)
+	require.NoError(t, err)
+	require.Equal(t, sdkmath.NewInt(60), storage.Balance("alice", "stake"))
+	require.Equal(t, sdkmath.NewInt(40), storage.Balance(escrow, "stake"))
+
+	require.NoError(t, module.OnTimeoutPacket(testChainName, packet))
+	require.Equal(t, sdkmath.NewInt(100), storage.Balance("alice", "stake"))
+	require.True(t, storage.Balance(escrow, "stake").IsZero())
+}
+
+func TestTransferRefundOnlyOnErrorAcknowledgement(t *testing.T) {
+	module, storage := newTestTransferModule(t)
+	escrow := smstorage.EscrowAccount(transfertypes.PortID, testChannelID)
+	require.NoError(t, storage.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+
+	packet := sentTransferPacket("stake", 40, "alice")
+	_, err := module.OnSendPacket(testChainName, packet)
+	require.NoError(t, err)
+
+	success := channeltypes.NewResultAcknowledgement([]byte{byte(1)})
+	require.NoError(t, module.OnAcknowledgementPacket(testChainName, packet, success.Acknowledgement()))
+	require.Equal(t, sdkmath.NewInt(60), storage.Balance("alice", "stake"))
+	require.Equal(t, sdkmath.NewInt(40), storage.Balance(escrow, "stake"), "the tokens stay escrowed after a successful transfer")
+
+	_, err = module.OnSendPacket(testChainName, packet)
+	require.NoError(t, err)
+	require.Equal(t, sdkmath.NewInt(20), storage.Balance("alice", "stake"))
+	require.Equal(t, sdkmath.NewInt(80), storage.Balance(escrow, "stake"))
+
+	failure := channeltypes.NewErrorAcknowledgement(transfertypes.ErrReceiveDisabled)
+	require.NoError(t, module.OnAcknowledgementPacket(testChainName, packet, failure.Acknowledgement()))
+	require.Equal(t, sdkmath.NewInt(60), storage.Balance("alice", "stake"))
+	require.Equal(t, sdkmath.NewInt(40), storage.Balance(escrow, "stake"))
+}
+
+func TestTransferInsufficientFunds(t *testing.T) {
+	module, storage := newTestTransferModule(t)
+	require.NoError(t, storage.MintCoins("alice", sdk.NewInt64Coin("stake", 10)))
+
+	_, err := module.OnSendPacket(testChainName, sentTransferPacket("stake", 11, "alice"))
+	require.ErrorIs(t, err, smstorage.ErrInsufficientFunds)
+	require.Equal(t, sdkmath.NewInt(10), storage.Balance("alice", "stake"))
+}
+
+func TestTransferVouchersBurnedAndRefunded(t *testing.T) {
+	module, storage := newTestTransferModule(t)
+
+	// Vouchers for uatom received from the chain
+	ack := recvTransferPacket(t, module, receivedTransferPacket("uatom", 50, "alice"))
+	require.True(t, ack.Success())
+	denomTrace := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(transfertypes.PortID, testChannelID, "uatom"))
+	require.Equal(t, sdkmath.NewInt(50), storage.Balance("alice", denomTrace.IBCDenom()))
+	require.True(t, storage.HasDenomTrace(denomTrace.Hash()))
+
+	// Sending them back burns them, and a timeout mints them again
+	packet := sentTransferPacket(denomTrace.GetFullDenomPath(), 20, "alice")
+	_, err := module.OnSendPacket(testChainName, packet)
+	require.NoError(t, err)
+	require.Equal(t, sdkmath.NewInt(30), storage.Balance("alice", denomTrace.IBCDenom()))
+	require.True(t, storage.Balance(smstorage.EscrowAccount(transfertypes.PortID, testChannelID), denomTrace.IBCDenom()).IsZero())
+
+	require.NoError(t, module.OnTimeoutPacket(testChainName, packet))
+	require.Equal(t, sdkmath.NewInt(50), storage.Balance("alice", denomTrace.IBCDenom()))
+}
+
+func TestTransferReceiveReturningTokensUnescrows(t *testing.T) {
+	module, storage := newTestTransferModule(t)
+	escrow := smstorage.EscrowAccount(transfertypes.PortID, testChannelID)
+	require.NoError(t, storage.MintCoins("alice", sdk.NewInt64Coin("stake", 100)))
+	_, err := module.OnSendPacket(testChainName, sentTransferPacket("stake", 40, "alice"))
+	require.NoError(t, err)
+
+	// The chain sends the stake vouchers back, prefixed with its end of the channel
+	ack := recvTransferPacket(t, module, receivedTransferPacket(transfertypes.GetPrefixedDenom(transfertypes.PortID, testCounterpartyChannel, "stake"), 15, "bob"))
+	require.True(t, ack.Success())
+	require.Equal(t, sdkmath.NewInt(15), storage.Balance("bob", "stake"))
+	require.Equal(t, sdkmath.NewInt(25), storage.Balance(escrow, "stake"))
+
+	// More than is escrowed is rejected with an error acknowledgement
+	ack = recvTransferPacket(t, module, receivedTransferPacket(transfertypes.GetPrefixedDenom(transfertypes.PortID, testCounterpartyChannel, "stake"), 26, "bob"))
+	require.False(t, ack.Success())
+	require.Equal(t, sdkmath.NewInt(15), storage.Balance("bob", "stake"))
+}
+
+// recvTransferPacket receives the packet and returns the acknowledgement
+func recvTransferPacket(t *testing.T, m transferModule, packet channeltypes.Packet) channeltypes.Acknowledgement {
+	t.Helper()
+	ack, err := m.OnRecvPacket(testChainName, packet)
+	require.NoError(t, err)
+
+	acknowledgement, ok := ack.(channeltypes.Acknowledgement)
+	require.True(t, ok)
+
+	return acknowledgement
+}
@@ -4,8 +4,16 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256r1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	"github.com/cosmos/cosmos-sdk/std"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	govv1beta1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1beta1"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	transfertypes "github.com/cosmos/ibc-go/v8/modules/apps/transfer/types"
 	ibcclienttypes "github.com/cosmos/ibc-go/v8/modules/core/02-client/types"
 	ibcconnectiontypes "github.com/cosmos/ibc-go/v8/modules/core/03-connection/types"
 	ibcchanneltypes "github.com/cosmos/ibc-go/v8/modules/core/04-channel/types"
@@ -18,11 +26,20 @@ func SetupCodec() codec.Codec {
 	interfaceRegistry := codectypes.NewInterfaceRegistry()
 	std.RegisterInterfaces(interfaceRegistry)
 	authtypes.RegisterInterfaces(interfaceRegistry)
+	// The messages of the modules most chains have, so they can be sent to interchain accounts
+	banktypes.RegisterInterfaces(interfaceRegistry)
+	distrtypes.RegisterInterfaces(interfaceRegistry)
+	govv1.RegisterInterfaces(interfaceRegistry)
+	govv1beta1.RegisterInterfaces(interfaceRegistry)
+	stakingtypes.RegisterInterfaces(interfaceRegistry)
+	transfertypes.RegisterInterfaces(interfaceRegistry)
 	ibcclienttypes.RegisterInterfaces(interfaceRegistry)
 	ibcconnectiontypes.RegisterInterfaces(interfaceRegistry)
 	ibcchanneltypes.RegisterInterfaces(interfaceRegistry)
 	solomachineclient.RegisterInterfaces(interfaceRegistry)
 	tmclient.RegisterInterfaces(interfaceRegistry)
+	// The sdk only registers the secp256r1 public key, but the solo machine stores secp256r1 private keys as well
+	interfaceRegistry.RegisterImplementations((*cryptotypes.PrivKey)(nil), &secp256r1.PrivKey{})
 	cdc := codec.NewProtoCodec(interfaceRegistry)
 
 	return cdc